@@ -0,0 +1,141 @@
+package main
+
+import (
+	"admin-api/handlers"
+	"admin-api/internal/gc"
+	"admin-api/internal/pricing"
+	"admin-api/internal/seed"
+	"admin-api/internal/storage"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// runCommand выполняет служебную команду вместо запуска HTTP-сервера:
+//
+//	go run . seed [-users data/users.json] [-fixtures data/fixtures]
+//	go run . gc-uploads [-delete] [-min-age 1h]
+//	go run . verify-uploads [-fix]
+//	go run . thumbnails
+//	go run . migrate-prices [-dry-run]
+func runCommand(dbConn *gorm.DB, uploads handlers.Uploads, name string, args []string) {
+	switch name {
+	case "seed":
+		runSeed(dbConn, args)
+	case "gc-uploads":
+		runGCUploads(dbConn, uploads.Storage, args)
+	case "verify-uploads":
+		runVerifyUploads(dbConn, uploads.Storage, args)
+	case "thumbnails":
+		runThumbnails(dbConn, uploads)
+	case "migrate-prices":
+		runMigratePrices(dbConn, args)
+	default:
+		log.Fatalf("Неизвестная команда %q, доступны: seed, gc-uploads, verify-uploads, thumbnails, migrate-prices", name)
+	}
+}
+
+func runSeed(dbConn *gorm.DB, args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	usersFile := fs.String("users", "data/users.json", "JSON-файл с пользователями")
+	fixturesDir := fs.String("fixtures", "data/fixtures", "папка с services.json, gallery.json, docs.json, locations.json, contacts.json")
+	fs.Parse(args)
+
+	report, err := seed.Run(dbConn, seed.Options{
+		UsersFile:   *usersFile,
+		FixturesDir: *fixturesDir,
+	})
+	if err != nil {
+		log.Fatal("Ошибка импорта:", err)
+	}
+
+	tables := make([]string, 0, len(report))
+	for table := range report {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		log.Printf("%s: %d записей", table, report[table])
+	}
+}
+
+func runGCUploads(dbConn *gorm.DB, store storage.Storage, args []string) {
+	fs := flag.NewFlagSet("gc-uploads", flag.ExitOnError)
+	remove := fs.Bool("delete", false, "удалить найденные осиротевшие файлы (по умолчанию только отчёт)")
+	minAge := fs.Duration("min-age", time.Hour, "не трогать файлы моложе этого возраста")
+	fs.Parse(args)
+
+	report, err := gc.Run(context.Background(), dbConn, store, gc.Options{
+		Remove: *remove,
+		MinAge: *minAge,
+	})
+	if err != nil {
+		log.Fatal("Ошибка сверки загрузок:", err)
+	}
+
+	for _, obj := range report.Orphans {
+		log.Printf("лишний файл: %s (%d байт, %s)", obj.Key, obj.Size, obj.ModTime.Format(time.RFC3339))
+	}
+	for _, key := range report.Missing {
+		log.Printf("нет файла, на который ссылается БД: %s", key)
+	}
+	log.Printf("в хранилище %d файлов, в БД ссылок %d, лишних %d, удалено %d, отсутствует %d",
+		report.Stored, report.Referenced, len(report.Orphans), report.Removed, len(report.Missing))
+}
+
+func runVerifyUploads(dbConn *gorm.DB, store storage.Storage, args []string) {
+	fs := flag.NewFlagSet("verify-uploads", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "исправить счётчики ссылок по фактическому использованию")
+	fs.Parse(args)
+
+	report, err := gc.Verify(context.Background(), dbConn, store, gc.VerifyOptions{Fix: *fix})
+	if err != nil {
+		log.Fatal("Ошибка проверки загрузок:", err)
+	}
+
+	for _, key := range report.Missing {
+		log.Printf("нет файла: %s", key)
+	}
+	for _, m := range report.Corrupt {
+		log.Printf("содержимое не совпадает: %s (ожидался sha256 %s, получен %s)", m.Key, m.Expected, m.Actual)
+	}
+	for _, rc := range report.RefCounts {
+		log.Printf("неверный счётчик ссылок: %s (в БД %d, используется %d)", rc.Key, rc.Stored, rc.Actual)
+	}
+	log.Printf("проверено %d файлов, отсутствует %d, повреждено %d, неверных счётчиков %d, исправлено %d",
+		report.Checked, len(report.Missing), len(report.Corrupt), len(report.RefCounts), report.Fixed)
+	if len(report.Missing) > 0 || len(report.Corrupt) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runThumbnails(dbConn *gorm.DB, uploads handlers.Uploads) {
+	galleryAPI := handlers.NewGalleryAPI(dbConn, uploads)
+	done, err := galleryAPI.GenerateMissingVariants(context.Background())
+	if err != nil {
+		log.Fatalf("Ошибка создания миниатюр (готово %d): %v", done, err)
+	}
+	log.Printf("Миниатюры, данные EXIF и очищенные оригиналы обновлены для %d фото", done)
+}
+
+func runMigratePrices(dbConn *gorm.DB, args []string) {
+	fs := flag.NewFlagSet("migrate-prices", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "только разобрать и показать отчёт, ничего не записывая")
+	fs.Parse(args)
+
+	report, err := pricing.MigrateLegacy(dbConn, pricing.MigrateOptions{DryRun: *dryRun})
+	if err != nil {
+		log.Fatal("Ошибка переноса цен:", err)
+	}
+
+	for _, f := range report.Failed {
+		log.Printf("не удалось разобрать цену услуги %d (%s): %v", f.ServiceID, f.Eng, f.Err)
+	}
+	log.Printf("перенесено услуг %d (цен %d), уже с прайсом %d, не разобрано %d",
+		report.Migrated, report.Created, report.Skipped, len(report.Failed))
+}
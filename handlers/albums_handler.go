@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"admin-api/internal/query"
+	"admin-api/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var albumsQuery = query.Spec{
+	Sort:    []string{"position", "id", "title", "slug"},
+	Filters: map[string]query.Kind{"visibility": query.String},
+	Default: "position",
+	Key:     "id",
+}
+
+type AlbumsAPI struct {
+	db *gorm.DB
+}
+
+func NewAlbumsAPI(db *gorm.DB) *AlbumsAPI {
+	return &AlbumsAPI{
+		db: db,
+	}
+}
+
+// AlbumRequest — тело запроса на создание и изменение альбома. Если position
+// не передан, новый альбом встаёт в конец, а у существующего порядок не меняется.
+type AlbumRequest struct {
+	Title      string `json:"title"`
+	Slug       string `json:"slug"`
+	CoverID    *int   `json:"cover_id"`
+	Visibility string `json:"visibility"`
+	Position   *int   `json:"position"`
+}
+
+// AlbumPhotosRequest — список фото галереи, добавляемых в альбом.
+type AlbumPhotosRequest struct {
+	IDs []int `json:"ids"`
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// GetAlbums godoc
+// @Summary      Получить список альбомов
+// @Description  Возвращает все альбомы в порядке position вместе с обложками
+// @Tags         albums
+// @Produce      json
+// @Param        limit  query int    false "Сколько записей вернуть (по умолчанию 100, максимум 500)"
+// @Param        offset query int    false "Сколько записей пропустить"
+// @Param        cursor query string false "Курсор из X-Next-Cursor предыдущей страницы"
+// @Param        sort       query string false "Сортировка: position, id, title, slug; -поле — по убыванию"
+// @Param        visibility query string false "Фильтр по видимости: public или hidden"
+// @Success      200  {array}  models.Albums
+// @Header       200  {integer} X-Total-Count "Всего записей"
+// @Failure      400  {object}  map[string]string "Неверный параметр"
+// @Failure      500  {object}  map[string]string
+// @Router       /albums [get]
+
+func (a *AlbumsAPI) GetAlbums(w http.ResponseWriter, r *http.Request) {
+	var albums []models.Albums
+	list(w, r.URL.Query(), a.db, albumsQuery, &albums, a.withCover)
+}
+
+// GetAlbum godoc
+// @Summary      Получить альбом
+// @Description  Ищет альбом по ID или по slug
+// @Tags         albums
+// @Produce      json
+// @Param        id   path string true "ID или slug альбома"
+// @Success      200 {object} models.Albums
+// @Failure      404 {object} map[string]string "Альбом не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /albums/{id} [get]
+
+func (a *AlbumsAPI) GetAlbum(w http.ResponseWriter, r *http.Request) {
+	album, ok := a.take(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(album)
+}
+
+// CreateAlbum godoc
+// @Summary      Создать альбом
+// @Tags         albums
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        album body AlbumRequest true "Новый альбом"
+// @Success      201 {object} models.Albums
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      409 {object} map[string]string "Slug уже занят"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /albums [post]
+
+func (a *AlbumsAPI) CreateAlbum(w http.ResponseWriter, r *http.Request) {
+	var req AlbumRequest
+	if !a.decode(w, r, &req) {
+		return
+	}
+	if a.slugTaken(w, req.Slug, 0) {
+		return
+	}
+
+	album := models.Albums{
+		Title:      req.Title,
+		Slug:       req.Slug,
+		CoverID:    req.CoverID,
+		Visibility: req.Visibility,
+	}
+	if req.Position != nil {
+		album.Position = *req.Position
+	} else if err := a.db.Model(&models.Albums{}).Select("COALESCE(MAX(position), 0) + 1").Scan(&album.Position).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if err := a.db.Create(&album).Error; err != nil {
+		writeAlbumError(w, err)
+		return
+	}
+
+	a.withCover(a.db).Take(&album, album.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(album)
+}
+
+// UpdateAlbum godoc
+// @Summary      Обновить альбом
+// @Tags         albums
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path int          true "ID альбома"
+// @Param        album body AlbumRequest true "Обновлённые данные"
+// @Success      200 {object} models.Albums
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      404 {object} map[string]string "Альбом не найден"
+// @Failure      409 {object} map[string]string "Slug уже занят"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /albums/{id} [put]
+
+func (a *AlbumsAPI) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
+	albumId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	var req AlbumRequest
+	if !a.decode(w, r, &req) {
+		return
+	}
+
+	album, ok := a.take(w, strconv.Itoa(albumId))
+	if !ok {
+		return
+	}
+	if a.slugTaken(w, req.Slug, albumId) {
+		return
+	}
+
+	updates := map[string]interface{}{
+		"title":      req.Title,
+		"slug":       req.Slug,
+		"cover_id":   req.CoverID,
+		"visibility": req.Visibility,
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	if err := a.db.Model(&models.Albums{ID: album.ID}).Updates(updates).Error; err != nil {
+		writeAlbumError(w, err)
+		return
+	}
+
+	album = models.Albums{}
+	a.withCover(a.db).Take(&album, albumId)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(album)
+}
+
+// DeleteAlbum godoc
+// @Summary      Удалить альбом
+// @Description  Удаляет альбом. Фото остаются в галерее.
+// @Tags         albums
+// @Security     ApiKeyAuth
+// @Param        id   path int true "ID альбома"
+// @Success      204 "Успешно удалено"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Альбом не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /albums/{id} [delete]
+
+func (a *AlbumsAPI) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
+	albumId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	result := a.db.Delete(&models.Albums{}, albumId)
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Альбом не найден", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddAlbumPhotos godoc
+// @Summary      Добавить фото в альбом
+// @Description  Добавляет в альбом уже загруженные фото. Фото, которые уже есть в альбоме, пропускаются.
+// @Tags         albums
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Param        id     path int                true "ID альбома"
+// @Param        photos body AlbumPhotosRequest true "ID фото"
+// @Success      204 "Фото добавлены"
+// @Failure      400 {object} map[string]string "Неверный JSON или неизвестное фото"
+// @Failure      404 {object} map[string]string "Альбом не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /albums/{id}/photos [post]
+
+func (a *AlbumsAPI) AddAlbumPhotos(w http.ResponseWriter, r *http.Request) {
+	albumId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	var req AlbumPhotosRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IDs) == 0 {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	if _, ok := a.take(w, strconv.Itoa(albumId)); !ok {
+		return
+	}
+
+	var found int64
+	if err := a.db.Model(&models.Gallery{}).Where("id IN ?", req.IDs).Distinct("id").Count(&found).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if int(found) != len(unique(req.IDs)) {
+		http.Error(w, "Некоторые фото не найдены", http.StatusBadRequest)
+		return
+	}
+
+	links := make([]models.AlbumPhotos, 0, len(req.IDs))
+	for _, id := range unique(req.IDs) {
+		links = append(links, models.AlbumPhotos{AlbumID: albumId, GalleryID: id})
+	}
+	err := a.db.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveAlbumPhoto godoc
+// @Summary      Убрать фото из альбома
+// @Description  Убирает фото из альбома, само фото остаётся в галерее
+// @Tags         albums
+// @Security     ApiKeyAuth
+// @Param        id      path int true "ID альбома"
+// @Param        photoId path int true "ID фото"
+// @Success      204 "Фото убрано"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Фото нет в альбоме"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /albums/{id}/photos/{photoId} [delete]
+
+func (a *AlbumsAPI) RemoveAlbumPhoto(w http.ResponseWriter, r *http.Request) {
+	albumId, ok := pathID(r, "id")
+	photoId, ok2 := pathID(r, "photoId")
+	if !ok || !ok2 {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	result := a.db.Where("album_id = ? AND gallery_id = ?", albumId, photoId).Delete(&models.AlbumPhotos{})
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Фото нет в альбоме", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *AlbumsAPI) withCover(db *gorm.DB) *gorm.DB {
+	return db.Preload("Cover").Preload("Cover.Variants", orderByWidth)
+}
+
+// take ищет альбом по ID или slug и сам отвечает 404/500, если не вышло.
+func (a *AlbumsAPI) take(w http.ResponseWriter, ref string) (models.Albums, bool) {
+	var album models.Albums
+	err := findAlbum(a.withCover(a.db), ref, &album)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Альбом не найден", http.StatusNotFound)
+		return album, false
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return album, false
+	}
+	return album, true
+}
+
+// decode читает AlbumRequest, подставляет значения по умолчанию и проверяет
+// поля. При ошибке сам отвечает 400.
+func (a *AlbumsAPI) decode(w http.ResponseWriter, r *http.Request, req *AlbumRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return false
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Slug = strings.TrimSpace(req.Slug)
+	if req.Visibility == "" {
+		req.Visibility = models.VisibilityPublic
+	}
+
+	if req.Title == "" || req.Slug == "" {
+		http.Error(w, "Название и slug обязательны", http.StatusBadRequest)
+		return false
+	}
+	if _, err := strconv.Atoi(req.Slug); err == nil || !slugPattern.MatchString(req.Slug) {
+		http.Error(w, "Slug может содержать только латинские буквы в нижнем регистре, цифры и дефисы и не может состоять из одних цифр", http.StatusBadRequest)
+		return false
+	}
+	if req.Visibility != models.VisibilityPublic && req.Visibility != models.VisibilityHidden {
+		http.Error(w, "Неизвестная видимость", http.StatusBadRequest)
+		return false
+	}
+	if req.CoverID != nil {
+		var count int64
+		if err := a.db.Model(&models.Gallery{}).Where("id = ?", *req.CoverID).Count(&count).Error; err != nil {
+			http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+			return false
+		}
+		if count == 0 {
+			http.Error(w, "Фото для обложки не найдено", http.StatusBadRequest)
+			return false
+		}
+	}
+	return true
+}
+
+// slugTaken отвечает 409, если slug занят другим альбомом. Это лишь быстрая
+// проверка до записи: уникальность slug проверяет сама БД, и одновременный
+// запрос с тем же slug получит 409 от writeAlbumError.
+func (a *AlbumsAPI) slugTaken(w http.ResponseWriter, slug string, exceptId int) bool {
+	var count int64
+	if err := a.db.Model(&models.Albums{}).Where("slug = ? AND id <> ?", slug, exceptId).Count(&count).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return true
+	}
+	if count > 0 {
+		http.Error(w, "Альбом с таким slug уже существует", http.StatusConflict)
+		return true
+	}
+	return false
+}
+
+// writeAlbumError отвечает 409, если slug успел занять другой альбом, и 400,
+// если фото обложки удалили из галереи между проверкой и записью.
+func writeAlbumError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		http.Error(w, "Альбом с таким slug уже существует", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		http.Error(w, "Фото для обложки не найдено", http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+}
+
+// findAlbum ищет альбом по ID, если ref — число, иначе по slug. Slug из одних
+// цифр запрещён, поэтому путаницы нет.
+func findAlbum(db *gorm.DB, ref string, album *models.Albums) error {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return db.Take(album, id).Error
+	}
+	return db.Where("slug = ?", ref).Take(album).Error
+}
+
+func unique(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
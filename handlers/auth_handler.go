@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"admin-api/internal/auth"
+	"admin-api/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuthAPI struct {
+	db         *gorm.DB
+	sessionTTL time.Duration
+}
+
+func NewAuthAPI(db *gorm.DB, sessionTTL time.Duration) *AuthAPI {
+	return &AuthAPI{
+		db:         db,
+		sessionTTL: sessionTTL,
+	}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      models.Users `json:"user"`
+}
+
+// Login godoc
+// @Summary      Вход в админку
+// @Description  Проверяет email и пароль и возвращает токен сессии для заголовка Authorization
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials body LoginRequest true "Email и пароль"
+// @Success      200 {object} LoginResponse
+// @Failure      400 {object} map[string]string "Неверный JSON"
+// @Failure      401 {object} map[string]string "Неверный email или пароль"
+// @Failure      403 {object} map[string]string "Учётная запись заблокирована"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /auth/login [post]
+
+func (a *AuthAPI) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		http.Error(w, "Email и пароль обязательны", http.StatusBadRequest)
+		return
+	}
+
+	var user models.Users
+	err := a.db.Where("email = ?", auth.NormalizeEmail(req.Email)).Take(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	// Для неизвестного email хеш пустой, но bcrypt всё равно выполняется:
+	// по времени ответа нельзя узнать, есть ли такой пользователь.
+	if err := auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
+		http.Error(w, "Неверный email или пароль", http.StatusUnauthorized)
+		return
+	}
+	if user.Status == models.StatusBlocked {
+		http.Error(w, "Учётная запись заблокирована", http.StatusForbidden)
+		return
+	}
+
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		http.Error(w, "Не удалось создать сессию", http.StatusInternalServerError)
+		return
+	}
+	session := models.Sessions{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(a.sessionTTL),
+	}
+	if err := a.db.Create(&session).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	a.db.Where("user_id = ? AND expires_at < ?", user.ID, time.Now()).Delete(&models.Sessions{})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoginResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	})
+}
+
+// Logout godoc
+// @Summary      Выход из админки
+// @Description  Завершает текущую сессию, токен больше не действителен
+// @Tags         auth
+// @Security     ApiKeyAuth
+// @Success      204 "Сессия завершена"
+// @Failure      401 {object} map[string]string "Требуется авторизация"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /auth/logout [post]
+
+func (a *AuthAPI) Logout(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	if token == "" {
+		http.Error(w, "Требуется авторизация", http.StatusUnauthorized)
+		return
+	}
+	if err := a.db.Where("token_hash = ?", auth.HashToken(token)).Delete(&models.Sessions{}).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me godoc
+// @Summary      Текущий пользователь
+// @Description  Возвращает пользователя, которому принадлежит токен
+// @Tags         auth
+// @Security     ApiKeyAuth
+// @Produce      json
+// @Success      200 {object} models.Users
+// @Failure      401 {object} map[string]string "Требуется авторизация"
+// @Router       /auth/me [get]
+
+func (a *AuthAPI) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.UserFromContext(r.Context()))
+}
+
+// RequireAuth пропускает запрос дальше только с действующим токеном сессии
+// и кладёт пользователя в контекст запроса.
+func (a *AuthAPI) RequireAuth(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			http.Error(w, "Требуется авторизация", http.StatusUnauthorized)
+			return
+		}
+
+		var session models.Sessions
+		err := a.db.Where("token_hash = ? AND expires_at > ?", auth.HashToken(token), time.Now()).Take(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Сессия недействительна", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+			return
+		}
+
+		var user models.Users
+		if err := a.db.Take(&user, session.UserID).Error; err != nil {
+			http.Error(w, "Сессия недействительна", http.StatusUnauthorized)
+			return
+		}
+		if user.Status == models.StatusBlocked {
+			http.Error(w, "Учётная запись заблокирована", http.StatusForbidden)
+			return
+		}
+
+		h(w, r.WithContext(auth.WithUser(r.Context(), &user)))
+	}
+}
+
+// Require оборачивает обработчик проверкой сессии и права perm.
+// Права объявляются при регистрации маршрута в main.go.
+func (a *AuthAPI) Require(perm auth.Permission, h http.HandlerFunc) http.HandlerFunc {
+	return a.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.Can(auth.UserFromContext(r.Context()), perm) {
+			http.Error(w, "Недостаточно прав", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	})
+}
+
+// bearerToken достаёт токен из заголовка Authorization. Принимается как
+// "Bearer <token>", так и просто "<token>" — так его отправляет Swagger UI.
+func bearerToken(r *http.Request) string {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return header
+}
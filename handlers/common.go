@@ -1,15 +1,35 @@
 package handlers
 
 import (
+	"admin-api/internal/query"
+	"admin-api/internal/validate"
+	"encoding/json"
+	"errors"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+
+	"gorm.io/gorm"
 )
 
+var allowedOrigins = []string{"http://localhost:3000"}
+
+// SetAllowedOrigins задаёт список origin, которым разрешены CORS-запросы.
+// "*" разрешает всех.
+func SetAllowedOrigins(origins []string) {
+	allowedOrigins = origins
+}
+
 func WithCORS(h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		if origin := allowedOrigin(r.Header.Get("Origin")); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -20,6 +40,18 @@ func WithCORS(h http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+func allowedOrigin(origin string) string {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && allowed == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
 func randomString(n int) string {
 	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	b := make([]byte, n)
@@ -28,3 +60,62 @@ func randomString(n int) string {
 	}
 	return string(b)
 }
+
+// ValidationError — ответ 400 с ошибками по отдельным полям формы.
+type ValidationError struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields"`
+}
+
+// writeValidationError отвечает 400 с картой «поле — ошибка», чтобы фронтенд
+// показал каждую ошибку рядом с её полем.
+func writeValidationError(w http.ResponseWriter, errs validate.Errors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ValidationError{
+		Error:  "Проверьте заполнение полей",
+		Fields: errs,
+	})
+}
+
+// pathID читает числовой параметр {id} из шаблона маршрута.
+func pathID(r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// list отвечает страницей списка по параметрам limit, offset, cursor, sort и
+// фильтрам из spec (см. internal/query). Тело — обычный JSON-массив, общее
+// число строк — в X-Total-Count, курсор следующей страницы — в X-Next-Cursor.
+func list(w http.ResponseWriter, values url.Values, db *gorm.DB, spec query.Spec, dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) {
+	if !findPage(w, values, db, spec, dest, scopes...) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dest)
+}
+
+// findPage — первая половина list для обработчиков, которым нужно дополнить
+// записи перед ответом: загружает страницу в dest и ставит заголовки.
+// Если вернула false, ответ с ошибкой уже отправлен.
+func findPage(w http.ResponseWriter, values url.Values, db *gorm.DB, spec query.Spec, dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) bool {
+	page, err := query.Find(db, values, spec, dest, scopes...)
+	var qe *query.Error
+	if errors.As(err, &qe) {
+		http.Error(w, "Неверный параметр "+qe.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return false
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.Next != "" {
+		w.Header().Set("X-Next-Cursor", page.Next)
+	}
+	return true
+}
@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"admin-api/internal/card"
+	"admin-api/internal/hours"
+	"admin-api/internal/validate"
 	"admin-api/models"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ContactsAPI struct {
@@ -19,83 +26,281 @@ func NewContactsAPI(db *gorm.DB) *ContactsAPI {
 	}
 }
 
+// ContactsPatch — тело PATCH /contacts: меняются только переданные поля.
+type ContactsPatch struct {
+	Address           *string              `json:"address"`
+	Phone             *string              `json:"phone"`
+	Email             *string              `json:"email"`
+	Website           *string              `json:"website"`
+	WorkSchedule      *string              `json:"work_schedule"`
+	SocialMediaVK     *string              `json:"social_media_vk"`
+	SocialMediaYa     *string              `json:"social_media_ya"`
+	SocialMediaTwoGis *string              `json:"social_media_two_gis"`
+	OpeningHours      *models.OpeningHours `json:"opening_hours"`
+}
+
+// fields отмечает переданные поля по их JSON-именам.
+func (p ContactsPatch) fields() map[string]bool {
+	return map[string]bool{
+		"address":              p.Address != nil,
+		"phone":                p.Phone != nil,
+		"email":                p.Email != nil,
+		"website":              p.Website != nil,
+		"work_schedule":        p.WorkSchedule != nil,
+		"social_media_vk":      p.SocialMediaVK != nil,
+		"social_media_ya":      p.SocialMediaYa != nil,
+		"social_media_two_gis": p.SocialMediaTwoGis != nil,
+		"opening_hours":        p.OpeningHours != nil,
+	}
+}
+
 // GetContacts godoc
 // @Summary      Получить контактную информацию
-// @Description  Возвращает запись с контактами
+// @Description  Возвращает контакты основной локации (см. /locations). Пока локаций нет, все поля пустые.
 // @Tags         contacts
-// @Security     ApiKeyAuth
-// @Accept       json
 // @Produce      json
-// @Param        contact body models.Contacts true "Обновлённые данные"
-// @Success      200  {array}  models.Contacts
-// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Success      200  {object}  models.Contacts
 // @Failure      500  {object}  map[string]string
 // @Router       /contacts [get]
 
 func (c *ContactsAPI) GetContacts(w http.ResponseWriter, r *http.Request) {
-	var items []models.Contacts
-	if err := c.db.Find(&items).Error; err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	location, ok := c.primary(w)
+	if !ok {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(items)
+	json.NewEncoder(w).Encode(location.Contacts())
 }
 
 // UpdateContacts godoc
 // @Summary      Обновить контактную информацию
-// @Description  Создаёт или обновляет единственную запись с контактами
+// @Description  Целиком заменяет контакты основной локации, а если локаций нет — создаёт её. График opening_hours меняется, только если передан; {} убирает его.
 // @Tags         contacts
 // @Security     ApiKeyAuth
 // @Accept       json
 // @Produce      json
 // @Param 		 contact body models.Contacts true "Обновлённые данные"
-// @Success      200  {array}  models.Contacts
-// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Success      200  {object}  models.Contacts
+// @Failure      400 {object} ValidationError "Ошибки по полям"
 // @Failure      500 {object} map[string]string "Ошибка БД"
 // @Router       /contacts [put]
 
 func (c *ContactsAPI) UpdateContacts(w http.ResponseWriter, r *http.Request) {
-
 	var updatedContacts models.Contacts
 	if err := json.NewDecoder(r.Body).Decode(&updatedContacts); err != nil {
 		http.Error(w, "Неверный JSON", http.StatusBadRequest)
 		return
 	}
 
-	if updatedContacts.Address == "" || updatedContacts.Phone == "" || updatedContacts.Email == "" {
-		http.Error(w, "Обязательные поля не могут быть пустыми", http.StatusBadRequest)
+	c.save(w, nil, func(contacts *models.Contacts) {
+		*contacts = updatedContacts
+	})
+}
+
+// PatchContacts godoc
+// @Summary      Изменить часть контактов
+// @Description  Меняет только переданные поля основной локации, остальные остаются прежними. Проверяются только переданные поля; если локации ещё нет, она создаётся и проверяются все.
+// @Tags         contacts
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        contact body handlers.ContactsPatch true "Изменённые поля"
+// @Success      200 {object} models.Contacts
+// @Failure      400 {object} ValidationError "Ошибки по полям"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /contacts [patch]
+
+func (c *ContactsAPI) PatchContacts(w http.ResponseWriter, r *http.Request) {
+	var patch ContactsPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
 		return
 	}
 
-	var existing models.Contacts
-	if err := c.db.First(&existing).Error; err != nil {
-		newContact := models.Contacts{
-			Address:           updatedContacts.Address,
-			Phone:             updatedContacts.Phone,
-			Email:             updatedContacts.Email,
-			Website:           updatedContacts.Website,
-			WorkSchedule:      updatedContacts.WorkSchedule,
-			SocialMediaVK:     updatedContacts.SocialMediaVK,
-			SocialMediaYa:     updatedContacts.SocialMediaYa,
-			SocialMediaTwoGis: updatedContacts.SocialMediaTwoGis,
+	c.save(w, patch.fields(), func(contacts *models.Contacts) {
+		set(&contacts.Address, patch.Address)
+		set(&contacts.Phone, patch.Phone)
+		set(&contacts.Email, patch.Email)
+		set(&contacts.Website, patch.Website)
+		set(&contacts.WorkSchedule, patch.WorkSchedule)
+		set(&contacts.SocialMediaVK, patch.SocialMediaVK)
+		set(&contacts.SocialMediaYa, patch.SocialMediaYa)
+		set(&contacts.SocialMediaTwoGis, patch.SocialMediaTwoGis)
+		if patch.OpeningHours != nil {
+			contacts.OpeningHours = patch.OpeningHours
 		}
-		if err := c.db.Create(&newContact).Error; err != nil {
-			http.Error(w, "Ошибка БД при создании", http.StatusInternalServerError)
-			return
+	})
+}
+
+// save применяет change к контактам основной локации под блокировкой строки
+// и сохраняет результат, если он прошёл проверку. Пока локаций нет, создаёт
+// основную. Если задан fields, ошибки в непереданных полях не мешают
+// сохранению: иначе PATCH упирался бы в старые данные, которые не трогал.
+func (c *ContactsAPI) save(w http.ResponseWriter, fields map[string]bool, change func(*models.Contacts)) {
+	var contacts models.Contacts
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var location models.Locations
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("is_primary").Take(&location).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			location = models.Locations{Name: models.DefaultLocationName, Primary: true, Position: 1}
+		} else if err != nil {
+			return err
+		}
+
+		contacts = location.Contacts()
+		change(&contacts)
+		errs := validateContacts(&contacts)
+		if fields != nil && location.ID != 0 {
+			for field := range errs {
+				if !fields[field] {
+					delete(errs, field)
+				}
+			}
+		}
+		if err := errs.Err(); err != nil {
+			return err
 		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(newContact)
+		location.SetContacts(contacts)
+		contacts = location.Contacts()
+		return tx.Save(&location).Error
+	})
+	var invalid validate.Errors
+	if errors.As(err, &invalid) {
+		writeValidationError(w, invalid)
 		return
 	}
-	result := c.db.Model(&existing).Where("1=1").Updates(updatedContacts)
-	if result.Error != nil {
-		fmt.Println("c.db.Model.Updates: ", result.Error)
-		http.Error(w, "Ошибка БД при обновлении", http.StatusInternalServerError)
+	if err != nil {
+		log.Println("contacts:", err)
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
 		return
 	}
 
-	c.db.Take(&existing)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(existing)
+	json.NewEncoder(w).Encode(contacts)
+}
+
+// GetOpenNow godoc
+// @Summary      Открыто ли сейчас
+// @Description  По графику основной локации сообщает, открыто ли сейчас, и когда это изменится (next_change, null — не в ближайший год)
+// @Tags         contacts
+// @Produce      json
+// @Success      200 {object} hours.Status
+// @Failure      404 {object} map[string]string "График работы не задан"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /contacts/open-now [get]
+
+func (c *ContactsAPI) GetOpenNow(w http.ResponseWriter, r *http.Request) {
+	location, ok := c.primary(w)
+	if !ok {
+		return
+	}
+	writeOpenStatus(w, location)
+}
+
+// GetContactsJSONLD godoc
+// @Summary      Контакты в разметке schema.org
+// @Description  Разметка JSON-LD типа Resort (LocalBusiness) по основной локации: адрес, телефон, координаты, график openingHours, праздничные дни и ссылки на соцсети в sameAs
+// @Tags         contacts
+// @Produce      json
+// @Success      200 {object} card.Business
+// @Failure      404 {object} map[string]string "Контакты не заполнены"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /contacts.jsonld [get]
+
+func (c *ContactsAPI) GetContactsJSONLD(w http.ResponseWriter, r *http.Request) {
+	location, ok := c.filled(w)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/ld+json")
+	json.NewEncoder(w).Encode(card.JSONLD(location, time.Now()))
+}
+
+// GetContactsVCard godoc
+// @Summary      Визитка в формате vCard
+// @Description  Файл vCard 4.0 с контактами основной локации для адресной книги
+// @Tags         contacts
+// @Produce      text/vcard
+// @Success      200 {file} file
+// @Failure      404 {object} map[string]string "Контакты не заполнены"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /contacts.vcf [get]
+
+func (c *ContactsAPI) GetContactsVCard(w http.ResponseWriter, r *http.Request) {
+	location, ok := c.filled(w)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="contacts.vcf"`)
+	io.WriteString(w, card.VCard(location))
+}
+
+// primary загружает основную локацию. Пока локаций нет, возвращает пустую.
+func (c *ContactsAPI) primary(w http.ResponseWriter) (models.Locations, bool) {
+	var location models.Locations
+	err := c.db.Where("is_primary").Take(&location).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return location, false
+	}
+	return location, true
+}
+
+// filled загружает основную локацию для экспорта и отвечает 404, если
+// экспортировать нечего.
+func (c *ContactsAPI) filled(w http.ResponseWriter) (models.Locations, bool) {
+	location, ok := c.primary(w)
+	if !ok {
+		return location, false
+	}
+	if location.ID == 0 {
+		http.Error(w, "Контакты не заполнены", http.StatusNotFound)
+		return location, false
+	}
+	return location, true
+}
+
+// writeOpenStatus отвечает статусом «открыто сейчас» по графику локации.
+func writeOpenStatus(w http.ResponseWriter, location models.Locations) {
+	if location.OpeningHours == nil {
+		http.Error(w, "График работы не задан", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hours.At(location.OpeningHours, time.Now()))
+}
+
+// validateContacts проверяет обязательные поля и приводит телефон к E.164,
+// а ссылки — к полному виду.
+func validateContacts(contacts *models.Contacts) validate.Errors {
+	errs := validate.Errors{}
+	errs.Required("address", contacts.Address)
+	errs.Required("phone", contacts.Phone)
+	errs.Required("email", contacts.Email)
+	errs.Phone("phone", &contacts.Phone)
+	errs.Email("email", &contacts.Email)
+	validateLinks(errs, &contacts.Website, &contacts.SocialMediaVK, &contacts.SocialMediaYa, &contacts.SocialMediaTwoGis)
+	if contacts.OpeningHours != nil && !contacts.OpeningHours.IsZero() {
+		if err := hours.Validate(contacts.OpeningHours); err != nil {
+			errs.Add("opening_hours", err.Error())
+		}
+	}
+	return errs
+}
+
+// validateLinks проверяет сайт и ссылки на соцсети — общие поля контактов и
+// локаций.
+func validateLinks(errs validate.Errors, website, vk, ya, twoGis *string) {
+	errs.URL("website", website)
+	errs.URL("social_media_vk", vk, validate.VKDomains...)
+	errs.URL("social_media_ya", ya, validate.YandexDomains...)
+	errs.URL("social_media_two_gis", twoGis, validate.TwoGisDomains...)
+}
+
+// set заменяет *dst, если значение передано.
+func set(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
 }
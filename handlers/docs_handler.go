@@ -1,27 +1,34 @@
 package handlers
 
 import (
+	"admin-api/internal/query"
 	"admin-api/models"
 	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
+	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var docsQuery = query.Spec{
+	Sort:    []string{"id", "name"},
+	Default: "id",
+	Key:     "id",
+}
+
 type DocsAPI struct {
-	db *gorm.DB
+	db      *gorm.DB
+	uploads Uploads
 }
 
-func NewDocsAPI(db *gorm.DB) *DocsAPI {
+func NewDocsAPI(db *gorm.DB, uploads Uploads) *DocsAPI {
 	return &DocsAPI{
-		db: db,
+		db:      db,
+		uploads: uploads,
 	}
 }
 
@@ -32,24 +39,26 @@ func NewDocsAPI(db *gorm.DB) *DocsAPI {
 // @Security     ApiKeyAuth
 // @Accept       json
 // @Produce      json
+// @Param        limit  query int    false "Сколько записей вернуть (по умолчанию 100, максимум 500)"
+// @Param        offset query int    false "Сколько записей пропустить"
+// @Param        cursor query string false "Курсор из X-Next-Cursor предыдущей страницы"
+// @Param        sort   query string false "Сортировка: id, name; -поле — по убыванию"
 // @Success      200  {array}  models.Docs
+// @Header       200  {integer} X-Total-Count "Всего записей"
+// @Failure      400  {object}  map[string]string "Неверный параметр"
 // @Failure      500  {object}  map[string]string
 // @Router       /docs [get]
 
 func (d *DocsAPI) GetDocs(w http.ResponseWriter, r *http.Request) {
 	var items []models.Docs
-	if err := d.db.Find(&items).Error; err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(items)
+	list(w, r.URL.Query(), d.db, docsQuery, &items)
 }
 
 // UpdateDocs godoc
 // @Summary      Обновить данные документа
 // @Description  Обновляет поля документа по ID. Возвращает обновлённую запись.
 // @Tags         docs
+// @Security     ApiKeyAuth
 // @Accept       mpfd
 // @Produce      json
 // @Param        id   path int               true "ID документа"
@@ -75,9 +84,9 @@ func (d *DocsAPI) UpdateDocs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = r.ParseMultipartForm(10 << 20)
+	err = d.uploads.parseForm(w, r)
 	if err != nil {
-		http.Error(w, "Ошибка парсинга формы", http.StatusBadRequest)
+		writeUploadError(w, err)
 		return
 	}
 
@@ -92,51 +101,48 @@ func (d *DocsAPI) UpdateDocs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fileHeader := files[0]
-	file, err := fileHeader.Open()
-	if err != nil {
-		http.Error(w, "Не удалось открыть файл", http.StatusInternalServerError)
-		return
+	var item models.Docs
+	var url, released string
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&item, docsId).Error; err != nil {
+			return err
+		}
+		oldFile := item.File
+		file, err := d.uploads.save(r, tx, fileHeader, kindDocs)
+		if err != nil {
+			return err
+		}
+		url = file.URL
+		if err := tx.Model(&item).Updates(models.Docs{
+			Name: fileHeader.Filename,
+			File: url,
+		}).Error; err != nil {
+			return err
+		}
+		// Старый файл удаляется после коммита и только если он больше
+		// нигде не используется.
+		released, err = d.uploads.blobs().Release(tx, oldFile)
+		return err
+	})
+	if err != nil && url != "" {
+		// Новый файл никому не нужен, если запись не обновилась.
+		d.uploads.discard(r.Context(), d.db, url)
 	}
-	defer file.Close()
-
-	ext := filepath.Ext(fileHeader.Filename)
-	filename := fmt.Sprintf("upload_%d_%s%s", time.Now().UnixNano(), randomString(6), ext)
-	dstPath := filepath.Join("uploads", filename)
-
-	if err := os.MkdirAll("uploads", os.ModePerm); err != nil {
-		http.Error(w, "Не удалось создать папку uploads", http.StatusInternalServerError)
+	var fe *fileError
+	if errors.As(err, &fe) {
+		writeUploadError(w, err)
 		return
 	}
-
-	dst, err := os.Create(dstPath)
-	if err != nil {
-		http.Error(w, "Не удалось создать файл", http.StatusInternalServerError)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Документ не найден", http.StatusNotFound)
 		return
 	}
-	defer dst.Close()
-
-	_, err = io.Copy(dst, file)
 	if err != nil {
-		http.Error(w, "Ошибка записи файла", http.StatusInternalServerError)
-		return
-	}
-
-	result := d.db.Model(&models.Docs{}).
-		Where("id = ?", docsId).
-		Updates(models.Docs{
-			Name: fileHeader.Filename,
-			File: "/uploads/" + filename,
-		})
-	if result.Error != nil {
 		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
 		return
 	}
-	if result.RowsAffected == 0 {
-		http.Error(w, "Документ не найден", http.StatusNotFound)
-		return
-	}
+	d.uploads.removeLater(r.Context(), d.db, released)
 
-	var item models.Docs
 	d.db.Take(&item, docsId)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(item)
@@ -147,6 +153,7 @@ func (d *DocsAPI) UpdateDocs(w http.ResponseWriter, r *http.Request) {
 // @Summary      Удалить документ
 // @Description  Удаляет документ по ID
 // @Tags         docs
+// @Security     ApiKeyAuth
 // @Accept       json
 // @Produce      json
 // @Param        id   path int               true "ID документа"
@@ -171,14 +178,24 @@ func (d *DocsAPI) DeleteDocs(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Неверный ID", http.StatusBadRequest)
 		return
 	}
-	result := d.db.Delete(&models.Docs{}, docsId)
-
-	if result.Error != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	var item models.Docs
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&item, docsId).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		// Файл освобождается последним: если хранилище недоступно, удаление
+		// строки откатится и запись не останется без файла.
+		return d.uploads.release(r.Context(), tx, item.File)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Документ не найден", http.StatusNotFound)
 		return
 	}
-	if result.RowsAffected == 0 {
-		http.Error(w, "Картинка не найдена", http.StatusNotFound)
+	if err != nil {
+		http.Error(w, "Ошибка БД или удаления файла", http.StatusInternalServerError)
 		return
 	}
 
@@ -187,65 +204,44 @@ func (d *DocsAPI) DeleteDocs(w http.ResponseWriter, r *http.Request) {
 
 // UploadDocsFiles godoc
 // @Summary      Создать новый документ
-// @Description  Создает новый документ
+// @Description  Создает документы из одного или нескольких файлов. Пакет загружается целиком:
+// @Description  если хоть один файл не удался, не сохраняется ни один.
 // @Tags         docs
+// @Security     ApiKeyAuth
 // @Accept       mpfd
 // @Produce      json
 // @Param        files formData file true "Документ создан"
-// @Success      200 {object} models.Docs
-// @Failure      400 {object} map[string]string "Неверный ID или JSON"
-// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Success      200 {array} models.Docs
+// @Failure      400 {object} UploadError "Неверный запрос"
+// @Failure      413 {object} UploadError "Файл или запрос слишком большой"
+// @Failure      415 {object} UploadError "Недопустимый тип файла"
+// @Failure      500 {object} UploadError "Ошибка БД или записи файла"
 // @Router       /docs [post]
 
 func (d *DocsAPI) UploadDocsFiles(w http.ResponseWriter, r *http.Request) {
 
-	r.ParseMultipartForm(10 << 20)
+	if err := d.uploads.parseForm(w, r); err != nil {
+		writeUploadError(w, err)
+		return
+	}
 
 	files := r.MultipartForm.File["files"]
 
 	var uploadedItems []models.Docs
-
-	for _, fileHeader := range files {
-
-		file, err := fileHeader.Open()
-		if err != nil {
-			http.Error(w, "Не удалось открыть файл", http.StatusInternalServerError)
-			return
-		}
-		defer file.Close()
-
-		ext := filepath.Ext(fileHeader.Filename)
-		filename := fmt.Sprintf("upload_%d_%s%s", time.Now().UnixNano(), randomString(6), ext)
-		dstPath := filepath.Join("uploads", filename)
-
-		if err := os.MkdirAll("uploads", os.ModePerm); err != nil {
-			http.Error(w, "Не удалось создать папку uploads", http.StatusInternalServerError)
-			return
-		}
-
-		dst, err := os.Create(dstPath)
-		if err != nil {
-			http.Error(w, "Не удалось создать файл", http.StatusInternalServerError)
-			return
-		}
-		defer dst.Close()
-
-		_, err = io.Copy(dst, file)
-		if err != nil {
-			http.Error(w, "Ошибка записи файла", http.StatusInternalServerError)
-			return
-		}
-
+	err := d.uploads.saveBatch(r, d.db, files, kindDocs, func(tx *gorm.DB, b *batch, fileHeader *multipart.FileHeader, file saved) error {
 		docsItem := models.Docs{
 			Name: fileHeader.Filename,
-			File: "/uploads/" + filename,
+			File: file.URL,
 		}
-		if err := d.db.Create(&docsItem).Error; err != nil {
-			http.Error(w, "Ошибка БД", http.StatusInternalServerError)
-			return
+		if err := tx.Create(&docsItem).Error; err != nil {
+			return err
 		}
-
 		uploadedItems = append(uploadedItems, docsItem)
+		return nil
+	})
+	if err != nil {
+		writeUploadError(w, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1,147 +1,346 @@
 package handlers
 
 import (
+	"admin-api/internal/filetype"
+	"admin-api/internal/imaging"
+	"admin-api/internal/query"
+	"admin-api/internal/storage"
 	"admin-api/models"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
 	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GalleryAPI struct {
-	db *gorm.DB
+	db      *gorm.DB
+	uploads Uploads
 }
 
-func NewGalleryAPI(db *gorm.DB) *GalleryAPI {
+func NewGalleryAPI(db *gorm.DB, uploads Uploads) *GalleryAPI {
 	return &GalleryAPI{
-		db: db,
+		db:      db,
+		uploads: uploads,
 	}
 }
 
 // GetGallery godoc
 // @Summary      Получить список изображений
-// @Description  Возвращает весь список изображений из БД
+// @Description  Возвращает изображения в порядке position. Параметр sort=taken_at сортирует по дате
+// @Description  съёмки (-taken_at — от новых к старым), фото без даты идут в конце. Старый параметр order
+// @Description  понимается как sort.
 // @Tags         gallery
 // @Security     ApiKeyAuth
-// @Accept       json
 // @Produce      json
-// @Param        gallery body models.Gallery true "Обновлённые данные"
+// @Param        limit  query int    false "Сколько записей вернуть (по умолчанию 100, максимум 500)"
+// @Param        offset query int    false "Сколько записей пропустить"
+// @Param        cursor query string false "Курсор из X-Next-Cursor предыдущей страницы"
+// @Param        sort   query string false "Сортировка: position, id, taken_at, width, height; -поле — по убыванию"
+// @Param        hidden query bool   false "Фильтр по скрытости"
+// @Param        album  query string false "Только фото из альбома (ID или slug)"
 // @Success      200  {array}  models.Gallery
+// @Header       200  {integer} X-Total-Count "Всего записей"
+// @Failure      400  {object}  map[string]string "Неверный параметр"
+// @Failure      404  {object}  map[string]string "Альбом не найден"
 // @Failure      500  {object}  map[string]string
 // @Router       /gallery [get]
 
 func (g *GalleryAPI) GetGallery(w http.ResponseWriter, r *http.Request) {
-	var items []models.Gallery
-	if err := g.db.Find(&items).Error; err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
-		return
+	values := r.URL.Query()
+	if order := values.Get("order"); order != "" && values.Get("sort") == "" {
+		values.Set("sort", order)
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(items)
+
+	db := g.db
+	if ref := values.Get("album"); ref != "" {
+		album, ok := g.album(w, ref)
+		if !ok {
+			return
+		}
+		db = db.Where("id IN (?)", g.db.Model(&models.AlbumPhotos{}).Select("gallery_id").Where("album_id = ?", album.ID))
+	}
+
+	var items []models.Gallery
+	list(w, values, db, galleryQuery, &items, preloadVariants)
+}
+
+// GalleryRequest — тело PUT /gallery/{id}. Меняются только переданные поля,
+// поэтому старый фронтенд, присылающий один hidden, не сотрёт подписи.
+type GalleryRequest struct {
+	Hidden  *bool   `json:"hidden"`
+	Caption *string `json:"caption"`
+	Alt     *string `json:"alt"`
+}
+
+// GalleryOrderRequest — новый порядок фото: перечисленные ID встают в начало
+// в указанном порядке, остальные сохраняют прежний порядок после них.
+type GalleryOrderRequest struct {
+	IDs []int `json:"ids"`
 }
 
 // UpdateGallery godoc
 // @Summary      Обновить данные изображения
-// @Description  Обновляет поля изображения по ID. Возвращает обновлённую запись.
+// @Description  Обновляет скрытие, подпись и alt-текст изображения по ID. Поля, которых нет в запросе, не меняются.
 // @Tags         gallery
+// @Security     ApiKeyAuth
 // @Accept       json
 // @Produce      json
-// @Param        id   path int               true "ID документа"
-// @Param        gallery body models.Gallery true "Обновлённые данные"
+// @Param        id   path int            true "ID изображения"
+// @Param        gallery body GalleryRequest true "Обновлённые данные"
 // @Success      200 {object} models.Gallery
 // @Failure      400 {object} map[string]string "Неверный ID или JSON"
-// @Failure      400 {object} map[string]string "Неверный ID или JSON"
-// @Failure      404 {object} map[string]string "Фото не найдено"
+// @Failure      404 {object} map[string]string "Картинка не найдена"
 // @Failure      500 {object} map[string]string "Ошибка БД"
 // @Router       /gallery/{id} [put]
 
 func (g *GalleryAPI) UpdateGallery(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
+	galleryId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
 
-	if !strings.HasPrefix(path, "/gallery/") {
-		http.Error(w, "Неверный URL", http.StatusBadRequest)
+	var req GalleryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
 		return
 	}
 
-	idStr := path[len("/gallery/"):]
+	updates := map[string]interface{}{}
+	if req.Hidden != nil {
+		updates["hidden"] = *req.Hidden
+	}
+	if req.Caption != nil {
+		updates["caption"] = strings.TrimSpace(*req.Caption)
+	}
+	if req.Alt != nil {
+		updates["alt"] = strings.TrimSpace(*req.Alt)
+	}
 
-	galleryId, err := strconv.Atoi(idStr)
-	if err != nil || galleryId <= 0 {
-		http.Error(w, "Неверный ID", http.StatusBadRequest)
+	var item models.Gallery
+	err := g.db.Take(&item, galleryId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Картинка не найдена", http.StatusNotFound)
 		return
 	}
+	if err == nil && len(updates) > 0 {
+		err = g.db.Model(&models.Gallery{ID: galleryId}).Updates(updates).Error
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+
+	g.db.Preload("Variants", orderByWidth).Take(&item, galleryId)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+
+}
+
+// ReorderGallery godoc
+// @Summary      Изменить порядок изображений
+// @Description  Переставляет фото за один запрос: перечисленные ID встают в начало галереи в указанном
+// @Description  порядке, остальные идут после них в прежнем порядке. Применяется целиком или никак.
+// @Description  Возвращает всю галерею в новом порядке, без постраничной разбивки.
+// @Tags         gallery
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        order body GalleryOrderRequest true "ID в новом порядке"
+// @Success      200 {array} models.Gallery
+// @Failure      400 {object} map[string]string "Неверный JSON, повтор или неизвестный ID"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /gallery/order [patch]
 
-	var updatedGallery models.Gallery
-	if err := json.NewDecoder(r.Body).Decode(&updatedGallery); err != nil {
+func (g *GalleryAPI) ReorderGallery(w http.ResponseWriter, r *http.Request) {
+	var req GalleryOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IDs) == 0 {
 		http.Error(w, "Неверный JSON", http.StatusBadRequest)
 		return
 	}
 
-	result := g.db.Model(&models.Gallery{}).
-		Where("id = ?", galleryId).
-		Updates(map[string]interface{}{
-			"hidden": updatedGallery.Hidden,
-		})
-	if result.Error != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	listed := make(map[int]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if listed[id] {
+			http.Error(w, fmt.Sprintf("ID %d указан дважды", id), http.StatusBadRequest)
+			return
+		}
+		listed[id] = true
+	}
+
+	var missing int
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGalleryOrder(tx); err != nil {
+			return err
+		}
+		var current []models.Gallery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "position").Order("position, id").Find(&current).Error; err != nil {
+			return err
+		}
+
+		positions := make(map[int]int, len(current))
+		for _, item := range current {
+			positions[item.ID] = item.Position
+		}
+		order := make([]int, 0, len(current))
+		for _, id := range req.IDs {
+			if _, ok := positions[id]; !ok {
+				missing = id
+				return gorm.ErrRecordNotFound
+			}
+			order = append(order, id)
+		}
+		for _, item := range current {
+			if !listed[item.ID] {
+				order = append(order, item.ID)
+			}
+		}
+
+		for i, id := range order {
+			if positions[id] == i+1 {
+				continue
+			}
+			if err := tx.Model(&models.Gallery{ID: id}).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, fmt.Sprintf("Картинка %d не найдена", missing), http.StatusBadRequest)
 		return
 	}
-	if result.RowsAffected == 0 {
-		http.Error(w, "Картинка не найдена", http.StatusNotFound)
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
 		return
 	}
 
-	var item models.Gallery
-	g.db.Take(&item, galleryId)
+	var items []models.Gallery
+	if err := g.db.Scopes(preloadVariants).Order("position, id").Find(&items).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(item)
+	json.NewEncoder(w).Encode(items)
+}
+
+var errGalleryInUse = errors.New("gallery: photo is used by services")
 
+// GalleryInUseError — ответ 409 на удаление фото, которое используют услуги.
+type GalleryInUseError struct {
+	Error    string       `json:"error"`
+	Services []ServiceRef `json:"services"`
+}
+
+type ServiceRef struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
 }
 
 // DeleteGallery godoc
 // @Summary      Удалить изображение
-// @Description  Удаляет изображение по ID
+// @Description  Удаляет изображение по ID. Если фото — обложка или картинка услуги или его адрес указан
+// @Description  в src услуги, отвечает 409 со списком таких услуг; с ?detach=true фото сначала
+// @Description  отвязывается от услуг, а такой src очищается.
 // @Tags         gallery
+// @Security     ApiKeyAuth
 // @Accept       json
 // @Produce      json
-// @Param        id   path int               true "ID изображения"
-// @Param        gallery body models.Gallery true "Обновлённые данные"
+// @Param        id     path  int  true  "ID изображения"
+// @Param        detach query bool false "Отвязать фото от услуг и удалить"
 // @Success      204 "Успешно удалено"
 // @Failure      400 {object} map[string]string "Неверный ID или JSON"
-// @Failure      404 {object} map[string]string "Фото не найдено"
+// @Failure      404 {object} map[string]string "Картинка не найдена"
+// @Failure      409 {object} GalleryInUseError "Фото используется услугами"
 // @Failure      500 {object} map[string]string "Ошибка БД"
 // @Router       /gallery/{id} [delete]
 
 func (g *GalleryAPI) DeleteGallery(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
-
-	if !strings.HasPrefix(path, "/gallery/") {
-		http.Error(w, "Неверный URL", http.StatusBadRequest)
+	galleryId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
 		return
 	}
+	detach := false
+	if raw := r.URL.Query().Get("detach"); raw != "" {
+		var err error
+		if detach, err = strconv.ParseBool(raw); err != nil {
+			http.Error(w, "Неверный параметр detach", http.StatusBadRequest)
+			return
+		}
+	}
 
-	idStr := path[len("/gallery/"):]
-
-	galleryId, err := strconv.Atoi(idStr)
-	if err != nil || galleryId <= 0 {
-		http.Error(w, "Неверный ID", http.StatusBadRequest)
+	var item models.Gallery
+	var usedBy []ServiceRef
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Variants").Take(&item, galleryId).Error; err != nil {
+			return err
+		}
+		// src услуги, введённый вручную, тоже может указывать на файл фото или
+		// его копии: без этой проверки файл удалился бы из-под услуги.
+		files := []string{item.Filename}
+		for _, v := range item.Variants {
+			files = append(files, v.Filename)
+		}
+		if err := tx.Model(&models.Services{}).Select("id", "title").
+			Where("cover_id = ? OR src IN ? OR id IN (?)", item.ID, files, tx.Model(&models.ServiceImages{}).Select("service_id").Where("gallery_id = ?", item.ID)).
+			Order("id").Find(&usedBy).Error; err != nil {
+			return err
+		}
+		if len(usedBy) > 0 && !detach {
+			return errGalleryInUse
+		}
+		if err := tx.Model(&models.Services{}).Where("cover_id = ?", item.ID).Update("cover_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Services{}).Where("src IN ?", files).Update("src", "").Error; err != nil {
+			return err
+		}
+		if err := tx.Where("gallery_id = ?", item.ID).Delete(&models.ServiceImages{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("gallery_id = ?", item.ID).Delete(&models.GalleryVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		// Файлы освобождаются последними: если хранилище недоступно, удаление
+		// строки откатится и запись не останется без файла.
+		for _, v := range item.Variants {
+			if err := g.uploads.release(r.Context(), tx, v.Filename); err != nil {
+				return err
+			}
+		}
+		return g.uploads.release(r.Context(), tx, item.Filename)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Картинка не найдена", http.StatusNotFound)
 		return
 	}
-	result := g.db.Delete(&models.Gallery{}, galleryId)
-
-	if result.Error != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	if errors.Is(err, errGalleryInUse) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(GalleryInUseError{
+			Error:    "Фото используется услугами, отвяжите его или удалите с ?detach=true",
+			Services: usedBy,
+		})
 		return
 	}
-	if result.RowsAffected == 0 {
-		http.Error(w, "Картинка не найдена", http.StatusNotFound)
+	if err != nil {
+		http.Error(w, "Ошибка БД или удаления файла", http.StatusInternalServerError)
 		return
 	}
 
@@ -150,71 +349,282 @@ func (g *GalleryAPI) DeleteGallery(w http.ResponseWriter, r *http.Request) {
 
 // UploadGalleryFiles godoc
 // @Summary      Загрузить фото в галерею
-// @Description  Принимает один или несколько файлов и сохраняет их
+// @Description  Принимает один или несколько файлов и сохраняет их. Пакет загружается целиком:
+// @Description  если хоть один файл не удался, не сохраняется ни один.
 // @Tags         gallery
+// @Security     ApiKeyAuth
 // @Accept       mpfd
 // @Produce      json
 // @Param        files formData file true "Фото для загрузки"
+// @Param        album query string false "Сразу добавить фото в альбом (ID или slug)"
 // @Success      200 {array} models.Gallery
-// @Failure      400 {object} map[string]string "Неверный запрос"
-// @Failure      500 {object} map[string]string "Ошибка БД или записи файла"
+// @Failure      400 {object} UploadError "Неверный запрос"
+// @Failure      404 {object} map[string]string "Альбом не найден"
+// @Failure      413 {object} UploadError "Файл или запрос слишком большой"
+// @Failure      415 {object} UploadError "Недопустимый тип файла"
+// @Failure      500 {object} UploadError "Ошибка БД или записи файла"
 // @Router       /gallery [post]
 
 func (g *GalleryAPI) UploadGalleryFiles(w http.ResponseWriter, r *http.Request) {
+	var album models.Albums
+	if ref := r.URL.Query().Get("album"); ref != "" {
+		var ok bool
+		if album, ok = g.album(w, ref); !ok {
+			return
+		}
+	}
 
-	err := r.ParseMultipartForm(10 << 20)
+	err := g.uploads.parseForm(w, r)
 	if err != nil {
-		http.Error(w, "Ошибка при парсинге запроса: "+err.Error(), http.StatusInternalServerError)
+		writeUploadError(w, err)
 		return
 	}
 
 	files := r.MultipartForm.File["files"]
 
 	var uploadedItems []models.Gallery
-
-	for _, fileHeader := range files {
-
-		file, err := fileHeader.Open()
+	var position int
+	err = g.uploads.saveBatch(r, g.db, files, kindGallery, func(tx *gorm.DB, b *batch, fileHeader *multipart.FileHeader, file saved) error {
+		photo := file.Photo
+		variants, err := g.saveVariants(r.Context(), tx, photo.Image, imaging.Formats)
+		for _, v := range variants {
+			b.track(v.Filename)
+		}
 		if err != nil {
-			http.Error(w, "Не удалось открыть файл", http.StatusInternalServerError)
-			return
+			return &fileError{status: http.StatusInternalServerError, message: "Не удалось создать миниатюры", err: err}
 		}
-		defer file.Close()
 
-		ext := filepath.Ext(fileHeader.Filename)
-		filename := fmt.Sprintf("upload_%d_%s%s", time.Now().UnixNano(), randomString(6), ext)
-		dstPath := filepath.Join("uploads", filename)
+		// Новые фото встают в конец галереи. Блокировка не даёт параллельной
+		// загрузке прочитать тот же MAX(position) до нашего коммита.
+		if position == 0 {
+			if err := lockGalleryOrder(tx); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Gallery{}).Select("COALESCE(MAX(position), 0)").Scan(&position).Error; err != nil {
+				return err
+			}
+		}
+		position++
 
-		if err := os.MkdirAll("uploads", os.ModePerm); err != nil {
-			http.Error(w, "Не удалось создать папку uploads", http.StatusInternalServerError)
-			return
+		galleryItem := models.Gallery{
+			Filename:    file.URL,
+			Hidden:      false,
+			Position:    position,
+			TakenAt:     photo.TakenAt,
+			Width:       photo.Width,
+			Height:      photo.Height,
+			Orientation: photo.Orientation,
+			Variants:    variants,
+		}
+		if err := tx.Create(&galleryItem).Error; err != nil {
+			return err
+		}
+		if album.ID != 0 {
+			link := models.AlbumPhotos{AlbumID: album.ID, GalleryID: galleryItem.ID}
+			if err := tx.Omit(clause.Associations).Create(&link).Error; err != nil {
+				return err
+			}
 		}
+		galleryItem.BuildSrcset()
+		uploadedItems = append(uploadedItems, galleryItem)
+		return nil
+	})
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
 
-		dst, err := os.Create(dstPath)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uploadedItems)
+}
+
+var galleryQuery = query.Spec{
+	Sort:    []string{"position", "id", "taken_at", "width", "height"},
+	Filters: map[string]query.Kind{"hidden": query.Bool},
+	Default: "position",
+	Key:     "id",
+}
+
+// album ищет альбом из параметра ?album= и сам отвечает 404/500, если не вышло.
+func (g *GalleryAPI) album(w http.ResponseWriter, ref string) (models.Albums, bool) {
+	var album models.Albums
+	err := findAlbum(g.db, ref, &album)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Альбом не найден", http.StatusNotFound)
+		return album, false
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return album, false
+	}
+	return album, true
+}
+
+// lockGalleryOrder упорядочивает транзакции, которые меняют позиции фото:
+// загрузки и PATCH /gallery/order. Блокировка снимается при завершении tx.
+func lockGalleryOrder(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext('gallery.position'))").Error
+}
+
+func orderByWidth(db *gorm.DB) *gorm.DB {
+	return db.Order("width")
+}
+
+func preloadVariants(db *gorm.DB) *gorm.DB {
+	return db.Preload("Variants", orderByWidth)
+}
+
+// saveVariants строит уменьшенные копии уже повёрнутого фото в форматах
+// formats и кладёт их в хранилище внутри транзакции tx. Миниатюры одинаковых фото совпадают
+// побайтно и хранятся один раз, как и оригиналы. Уже записанные варианты
+// возвращаются и при ошибке, чтобы вызывающий мог их удалить.
+func (g *GalleryAPI) saveVariants(ctx context.Context, tx *gorm.DB, img image.Image, formats []string) ([]models.GalleryVariant, error) {
+	generated, err := imaging.Variants(img, formats)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []models.GalleryVariant
+	for _, v := range generated {
+		variantURL, err := g.uploads.put(ctx, tx, v.Data, v.MimeType(), filetype.Ext(v.MimeType()))
 		if err != nil {
-			http.Error(w, "Не удалось создать файл", http.StatusInternalServerError)
-			return
+			return variants, err
 		}
-		defer dst.Close()
+		variants = append(variants, models.GalleryVariant{
+			Width:    v.Width,
+			Height:   v.Height,
+			Format:   v.Format,
+			Filename: variantURL,
+		})
+	}
+	return variants, nil
+}
 
-		_, err = io.Copy(dst, file)
-		if err != nil {
-			http.Error(w, "Ошибка записи файла", http.StatusInternalServerError)
-			return
+// missingFormats возвращает форматы из imaging.Formats, в которых у фото ещё
+// нет миниатюр.
+func missingFormats(variants []models.GalleryVariant) []string {
+	have := map[string]bool{}
+	for _, v := range variants {
+		have[v.Format] = true
+	}
+	var missing []string
+	for _, format := range imaging.Formats {
+		if !have[format] {
+			missing = append(missing, format)
 		}
+	}
+	return missing
+}
 
-		galleryItem := models.Gallery{
-			Filename: "/uploads/" + filename,
-			Hidden:   false,
+// GenerateMissingVariants доводит фото, загруженные до появления обработки,
+// до текущего вида: создаёт миниатюры в недостающих форматах (например, WebP
+// для фото, обработанных сборкой без него), заполняет поля из EXIF и заменяет
+// оригинал копией без метаданных, чтобы по /uploads/ больше не отдавались
+// координаты съёмки. Очищенный оригинал сохраняется через blobs, ссылка на
+// прежний файл снимается, и он удаляется, если больше никому не нужен.
+// Просматривает всю галерею; уже обработанные фото не меняются. Возвращает
+// число изменённых фото.
+func (g *GalleryAPI) GenerateMissingVariants(ctx context.Context) (int, error) {
+	var items []models.Gallery
+	if err := g.db.WithContext(ctx).Preload("Variants").Order("id").Find(&items).Error; err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, item := range items {
+		key, ok := storage.KeyOf(g.uploads.Storage, item.Filename)
+		if !ok {
+			continue
 		}
-		if err := g.db.Create(&galleryItem).Error; err != nil {
-			http.Error(w, "Ошибка БД", http.StatusInternalServerError)
-			return
+		file, err := g.uploads.Storage.Get(ctx, key)
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Printf("фото %d: нет файла %s", item.ID, item.Filename)
+			continue
+		}
+		if err != nil {
+			return done, err
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return done, err
+		}
+		photo, err := imaging.Prepare(data, http.DetectContentType(data), g.uploads.MaxPixels)
+		if errors.Is(err, imaging.ErrCorrupt) {
+			log.Printf("фото %d: не удалось прочитать изображение %s", item.ID, item.Filename)
+			continue
+		}
+		if errors.Is(err, imaging.ErrTooLarge) {
+			log.Printf("фото %d: больше %d пикселей, пропущено: %s", item.ID, g.uploads.MaxPixels, item.Filename)
+			continue
+		}
+		if err != nil {
+			return done, err
 		}
 
-		uploadedItems = append(uploadedItems, galleryItem)
-	}
+		strip := !bytes.Equal(photo.Data, data)
+		missing := missingFormats(item.Variants)
+		if !strip && len(missing) == 0 && item.Width != 0 {
+			continue
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(uploadedItems)
+		var (
+			variants []models.GalleryVariant
+			original string
+			released string
+		)
+		err = g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if strip {
+				var err error
+				original, err = g.uploads.put(ctx, tx, photo.Data, photo.MimeType, filetype.Ext(photo.MimeType))
+				if err != nil {
+					return err
+				}
+				if released, err = g.uploads.blobs().Release(tx, item.Filename); err != nil {
+					return err
+				}
+				if err := tx.Model(&models.Gallery{ID: item.ID}).Update("filename", original).Error; err != nil {
+					return err
+				}
+				// Услуги со старым src, введённым вручную, переводим на новый адрес.
+				if err := tx.Model(&models.Services{}).Where("src = ?", item.Filename).Update("src", original).Error; err != nil {
+					return err
+				}
+			}
+			if len(missing) > 0 {
+				var err error
+				variants, err = g.saveVariants(ctx, tx, photo.Image, missing)
+				if err != nil {
+					return err
+				}
+				for i := range variants {
+					variants[i].GalleryID = item.ID
+				}
+				if err := tx.Create(&variants).Error; err != nil {
+					return err
+				}
+			}
+			if item.Width != 0 {
+				return nil
+			}
+			return tx.Model(&models.Gallery{ID: item.ID}).Updates(map[string]interface{}{
+				"taken_at":    photo.TakenAt,
+				"width":       photo.Width,
+				"height":      photo.Height,
+				"orientation": photo.Orientation,
+			}).Error
+		})
+		if err != nil {
+			if original != "" {
+				g.uploads.discard(ctx, g.db, original)
+			}
+			for _, v := range variants {
+				g.uploads.discard(ctx, g.db, v.Filename)
+			}
+			return done, err
+		}
+		g.uploads.removeLater(ctx, g.db, released)
+		done++
+	}
+	return done, nil
 }
@@ -0,0 +1,406 @@
+package handlers
+
+import (
+	"admin-api/internal/hours"
+	"admin-api/internal/query"
+	"admin-api/internal/validate"
+	"admin-api/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var locationsQuery = query.Spec{
+	Sort:    []string{"position", "id", "name"},
+	Default: "position",
+	Key:     "id",
+}
+
+var errPrimaryRequired = errors.New("locations: primary location cannot be unset")
+
+type LocationsAPI struct {
+	db *gorm.DB
+}
+
+func NewLocationsAPI(db *gorm.DB) *LocationsAPI {
+	return &LocationsAPI{
+		db: db,
+	}
+}
+
+// LocationRequest — тело POST и PUT /locations. Position можно не
+// передавать: новая локация встаёт в конец, у существующей порядок не меняется.
+// С opening_hours так же, как в PUT /contacts: без поля (или с null) график
+// остаётся прежним, пустой объект {} его убирает.
+type LocationRequest struct {
+	Name              string               `json:"name"`
+	Address           string               `json:"address"`
+	Latitude          *float64             `json:"latitude"`
+	Longitude         *float64             `json:"longitude"`
+	Phones            []string             `json:"phones"`
+	Emails            []string             `json:"emails"`
+	Website           string               `json:"website"`
+	WorkSchedule      string               `json:"work_schedule"`
+	SocialMediaVK     string               `json:"social_media_vk"`
+	SocialMediaYa     string               `json:"social_media_ya"`
+	SocialMediaTwoGis string               `json:"social_media_two_gis"`
+	OpeningHours      *models.OpeningHours `json:"opening_hours"`
+	Primary           bool                 `json:"primary"`
+	Position          *int                 `json:"position"`
+}
+
+// GetLocations godoc
+// @Summary      Список локаций
+// @Description  Площадки базы отдыха в порядке position
+// @Tags         locations
+// @Produce      json
+// @Param        limit  query int    false "Сколько записей вернуть (по умолчанию 100, максимум 500)"
+// @Param        offset query int    false "Сколько записей пропустить"
+// @Param        cursor query string false "Курсор из X-Next-Cursor предыдущей страницы"
+// @Param        sort   query string false "Сортировка: position, id, name; -поле — по убыванию"
+// @Success      200 {array}  models.Locations
+// @Header       200 {integer} X-Total-Count "Всего записей"
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /locations [get]
+
+func (l *LocationsAPI) GetLocations(w http.ResponseWriter, r *http.Request) {
+	var locations []models.Locations
+	list(w, r.URL.Query(), l.db, locationsQuery, &locations)
+}
+
+// GetLocation godoc
+// @Summary      Получить локацию
+// @Tags         locations
+// @Produce      json
+// @Param        id   path int true "ID локации"
+// @Success      200 {object} models.Locations
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Локация не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /locations/{id} [get]
+
+func (l *LocationsAPI) GetLocation(w http.ResponseWriter, r *http.Request) {
+	locationId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	l.respond(w, locationId)
+}
+
+// GetLocationOpenNow godoc
+// @Summary      Открыта ли локация сейчас
+// @Tags         locations
+// @Produce      json
+// @Param        id   path int true "ID локации"
+// @Success      200 {object} hours.Status
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Локация не найдена или график не задан"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /locations/{id}/open-now [get]
+
+func (l *LocationsAPI) GetLocationOpenNow(w http.ResponseWriter, r *http.Request) {
+	locationId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	var location models.Locations
+	err := l.db.Take(&location, locationId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Локация не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	writeOpenStatus(w, location)
+}
+
+// CreateLocation godoc
+// @Summary      Добавить локацию
+// @Description  Первая локация становится основной автоматически. Если новая отмечена primary, отметка снимается с прежней основной.
+// @Tags         locations
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        location body handlers.LocationRequest true "Новая локация"
+// @Success      201 {object} models.Locations
+// @Failure      400 {object} ValidationError "Ошибки по полям"
+// @Failure      409 {object} map[string]string "Основной одновременно отмечена другая локация"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /locations [post]
+
+func (l *LocationsAPI) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var req LocationRequest
+	if !decodeLocation(w, r, &req) {
+		return
+	}
+
+	location := req.location()
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		var primaries int64
+		if err := tx.Model(&models.Locations{}).Where("is_primary").Count(&primaries).Error; err != nil {
+			return err
+		}
+		if primaries == 0 {
+			location.Primary = true
+		} else if location.Primary {
+			if err := unsetPrimary(tx, 0); err != nil {
+				return err
+			}
+		}
+		if req.Position != nil {
+			location.Position = *req.Position
+		} else if err := tx.Model(&models.Locations{}).Select("COALESCE(MAX(position), 0) + 1").Scan(&location.Position).Error; err != nil {
+			return err
+		}
+		return tx.Create(&location).Error
+	})
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(location)
+}
+
+// UpdateLocation godoc
+// @Summary      Обновить локацию
+// @Description  Заменяет все поля локации, кроме графика opening_hours: без него график не меняется, а {} убирает график. Отметить основной можно любую локацию, а снять отметку — только отметив другую.
+// @Tags         locations
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path int                      true "ID локации"
+// @Param        location body handlers.LocationRequest true "Обновлённые данные"
+// @Success      200 {object} models.Locations
+// @Failure      400 {object} ValidationError "Ошибки по полям"
+// @Failure      404 {object} map[string]string "Локация не найдена"
+// @Failure      409 {object} map[string]string "Нельзя снять отметку с основной локации"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /locations/{id} [put]
+
+func (l *LocationsAPI) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	locationId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	var req LocationRequest
+	if !decodeLocation(w, r, &req) {
+		return
+	}
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Locations
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&current, locationId).Error; err != nil {
+			return err
+		}
+		if current.Primary && !req.Primary {
+			return errPrimaryRequired
+		}
+		if req.Primary && !current.Primary {
+			if err := unsetPrimary(tx, locationId); err != nil {
+				return err
+			}
+		}
+
+		location := req.location()
+		location.ID = locationId
+		if req.OpeningHours == nil {
+			location.OpeningHours = current.OpeningHours
+		}
+		location.Position = current.Position
+		if req.Position != nil {
+			location.Position = *req.Position
+		}
+		return tx.Select("*").Save(&location).Error
+	})
+	if errors.Is(err, errPrimaryRequired) {
+		http.Error(w, "Нельзя снять отметку с основной локации — отметьте основной другую", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	l.respond(w, locationId)
+}
+
+// DeleteLocation godoc
+// @Summary      Удалить локацию
+// @Description  Основную локацию можно удалить, только если она последняя: иначе сначала отметьте основной другую.
+// @Tags         locations
+// @Security     ApiKeyAuth
+// @Param        id   path int true "ID локации"
+// @Success      204 "Успешно удалено"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Локация не найдена"
+// @Failure      409 {object} map[string]string "Локация основная"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /locations/{id} [delete]
+
+func (l *LocationsAPI) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	locationId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		var location models.Locations
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&location, locationId).Error; err != nil {
+			return err
+		}
+		if location.Primary {
+			var others int64
+			if err := tx.Model(&models.Locations{}).Where("id <> ?", locationId).Count(&others).Error; err != nil {
+				return err
+			}
+			if others > 0 {
+				return errPrimaryRequired
+			}
+		}
+		return tx.Delete(&location).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Локация не найдена", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errPrimaryRequired) {
+		http.Error(w, "Нельзя удалить основную локацию — сначала отметьте основной другую", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (l *LocationsAPI) respond(w http.ResponseWriter, locationId int) {
+	var location models.Locations
+	err := l.db.Take(&location, locationId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Локация не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
+}
+
+// writeLocationError переводит ошибки записи в ответы: отметку primary
+// одновременно получила другая локация — 409, данные не прошли ограничения
+// БД — 400.
+func writeLocationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		http.Error(w, "Локация не найдена", http.StatusNotFound)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		http.Error(w, "Основной одновременно отмечена другая локация — повторите запрос", http.StatusConflict)
+	case errors.Is(err, gorm.ErrCheckConstraintViolated), errors.Is(err, gorm.ErrInvalidData):
+		http.Error(w, "Неверные данные локации", http.StatusBadRequest)
+	default:
+		log.Println("locations:", err)
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	}
+}
+
+// unsetPrimary снимает отметку «основная» со всех локаций, кроме except.
+// Вызывается до того, как отметку получит другая локация, иначе сработает
+// уникальный индекс.
+func unsetPrimary(tx *gorm.DB, except int) error {
+	return tx.Model(&models.Locations{}).Where("is_primary AND id <> ?", except).Update("is_primary", false).Error
+}
+
+func decodeLocation(w http.ResponseWriter, r *http.Request, req *LocationRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Address = strings.TrimSpace(req.Address)
+	req.Phones = compact(req.Phones)
+	req.Emails = compact(req.Emails)
+
+	errs := validate.Errors{}
+	errs.Required("name", req.Name)
+	errs.Required("address", req.Address)
+	switch {
+	case (req.Latitude == nil) != (req.Longitude == nil):
+		errs.Add("latitude", "широта и долгота задаются вместе")
+	case req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90):
+		errs.Add("latitude", "широта должна быть от -90 до 90")
+	case req.Longitude != nil && (*req.Longitude < -180 || *req.Longitude > 180):
+		errs.Add("longitude", "долгота должна быть от -180 до 180")
+	}
+	for i := range req.Phones {
+		errs.Phone(fmt.Sprintf("phones[%d]", i), &req.Phones[i])
+	}
+	for i := range req.Emails {
+		errs.Email(fmt.Sprintf("emails[%d]", i), &req.Emails[i])
+	}
+	validateLinks(errs, &req.Website, &req.SocialMediaVK, &req.SocialMediaYa, &req.SocialMediaTwoGis)
+	if req.OpeningHours != nil && !req.OpeningHours.IsZero() {
+		if err := hours.Validate(req.OpeningHours); err != nil {
+			errs.Add("opening_hours", err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		writeValidationError(w, errs)
+		return false
+	}
+	return true
+}
+
+// location собирает локацию из запроса. Пустой график {} убирает его;
+// непереданный UpdateLocation берёт из текущей записи.
+func (req LocationRequest) location() models.Locations {
+	openingHours := req.OpeningHours
+	if openingHours != nil && openingHours.IsZero() {
+		openingHours = nil
+	}
+	return models.Locations{
+		Name:              req.Name,
+		Address:           req.Address,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		Phones:            req.Phones,
+		Emails:            req.Emails,
+		Website:           req.Website,
+		WorkSchedule:      req.WorkSchedule,
+		SocialMediaVK:     req.SocialMediaVK,
+		SocialMediaYa:     req.SocialMediaYa,
+		SocialMediaTwoGis: req.SocialMediaTwoGis,
+		OpeningHours:      openingHours,
+		Primary:           req.Primary,
+	}
+}
+
+// compact обрезает пробелы и убирает пустые строки из списка.
+func compact(list []string) []string {
+	out := make([]string, 0, len(list))
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
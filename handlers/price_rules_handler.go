@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"admin-api/internal/pricing"
+	"admin-api/models"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PriceRuleRequest — тело POST и PUT /services/{id}/price-rules.
+type PriceRuleRequest struct {
+	Label    string          `json:"label"`
+	DateFrom *models.Date    `json:"date_from"`
+	DateTo   *models.Date    `json:"date_to"`
+	Weekdays models.Weekdays `json:"weekdays"`
+	Priority int             `json:"priority"`
+	Amount   int64           `json:"amount"`
+	Currency string          `json:"currency"`
+	Unit     string          `json:"unit"`
+}
+
+// GetPriceRules godoc
+// @Summary      Календарь цен услуги
+// @Description  Возвращает правила календаря цен в порядке начала действия
+// @Tags         services
+// @Produce      json
+// @Param        id   path int true "ID услуги"
+// @Success      200 {array}  models.PriceRules
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/price-rules [get]
+
+func (p *PricesAPI) GetPriceRules(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := p.service(w, r)
+	if !ok {
+		return
+	}
+
+	var rules []models.PriceRules
+	err := p.db.Where("service_id = ?", serviceId).Order("date_from NULLS FIRST, priority DESC, id").Find(&rules).Error
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// CreatePriceRule godoc
+// @Summary      Добавить правило календаря цен
+// @Description  Правило задаёт цену на период и дни недели (1 — понедельник, 7 — воскресенье). Из нескольких подходящих правил срабатывает правило с большим priority. Правило с date_from в будущем — запланированное изменение цены.
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                       true "ID услуги"
+// @Param        rule body handlers.PriceRuleRequest true "Новое правило"
+// @Success      201 {object} models.PriceRules
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/price-rules [post]
+
+func (p *PricesAPI) CreatePriceRule(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := p.service(w, r)
+	if !ok {
+		return
+	}
+	rule, ok := decodePriceRule(w, r)
+	if !ok {
+		return
+	}
+
+	rule.ServiceID = serviceId
+	if err := p.db.Create(&rule).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdatePriceRule godoc
+// @Summary      Изменить правило календаря цен
+// @Description  Заменяет все поля правила
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path int                       true "ID услуги"
+// @Param        ruleId path int                       true "ID правила"
+// @Param        rule   body handlers.PriceRuleRequest true "Обновлённые данные"
+// @Success      200 {object} models.PriceRules
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      404 {object} map[string]string "Правило не найдено"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/price-rules/{ruleId} [put]
+
+func (p *PricesAPI) UpdatePriceRule(w http.ResponseWriter, r *http.Request) {
+	serviceId, ruleId, ok := ruleIDs(w, r)
+	if !ok {
+		return
+	}
+	rule, ok := decodePriceRule(w, r)
+	if !ok {
+		return
+	}
+
+	result := p.db.Model(&models.PriceRules{}).Where("id = ? AND service_id = ?", ruleId, serviceId).Updates(map[string]interface{}{
+		"label":     rule.Label,
+		"date_from": rule.DateFrom,
+		"date_to":   rule.DateTo,
+		"weekdays":  rule.Weekdays,
+		"priority":  rule.Priority,
+		"amount":    rule.Amount,
+		"currency":  rule.Currency,
+		"unit":      rule.Unit,
+	})
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Правило не найдено", http.StatusNotFound)
+		return
+	}
+
+	rule.ID = ruleId
+	rule.ServiceID = serviceId
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeletePriceRule godoc
+// @Summary      Удалить правило календаря цен
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Param        id     path int true "ID услуги"
+// @Param        ruleId path int true "ID правила"
+// @Success      204 "Успешно удалено"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Правило не найдено"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/price-rules/{ruleId} [delete]
+
+func (p *PricesAPI) DeletePriceRule(w http.ResponseWriter, r *http.Request) {
+	serviceId, ruleId, ok := ruleIDs(w, r)
+	if !ok {
+		return
+	}
+
+	result := p.db.Where("service_id = ?", serviceId).Delete(&models.PriceRules{}, ruleId)
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Правило не найдено", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetEffectivePrice godoc
+// @Summary      Цена услуги на дату
+// @Description  Возвращает цену с учётом календаря: сработавшее правило (rule_id) или, если правил на эту дату нет, самую низкую цену прайса, действующую в этот день (price_id)
+// @Tags         services
+// @Produce      json
+// @Param        id   path  int    true  "ID услуги"
+// @Param        date query string false "Дата в формате ГГГГ-ММ-ДД, по умолчанию сегодня по часовому поясу основной площадки"
+// @Success      200 {object} pricing.Quote
+// @Failure      400 {object} map[string]string "Неверный ID или дата"
+// @Failure      404 {object} map[string]string "Услуга не найдена или цена на дату не задана"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/price [get]
+
+func (p *PricesAPI) GetEffectivePrice(w http.ResponseWriter, r *http.Request) {
+	day, err := pricing.Today(p.db, time.Now())
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if raw := r.URL.Query().Get("date"); raw != "" {
+		parsed, err := models.ParseDate(raw)
+		if err != nil {
+			http.Error(w, "Неверная дата: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		day = parsed
+	}
+	serviceId, ok := p.service(w, r)
+	if !ok {
+		return
+	}
+
+	var prices []models.ServicePrices
+	var rules []models.PriceRules
+	if err := p.db.Where("service_id = ?", serviceId).Find(&prices).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if err := p.db.Where("service_id = ?", serviceId).Find(&rules).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+
+	quote, ok := pricing.Effective(prices, rules, day)
+	if !ok {
+		http.Error(w, "Цена на эту дату не задана", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+func ruleIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	ruleId, ok := pathID(r, "ruleId")
+	if !ok {
+		http.Error(w, "Неверный ID правила", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return serviceId, ruleId, true
+}
+
+// decodePriceRule читает и проверяет PriceRuleRequest.
+func decodePriceRule(w http.ResponseWriter, r *http.Request) (models.PriceRules, bool) {
+	var req PriceRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON: "+err.Error(), http.StatusBadRequest)
+		return models.PriceRules{}, false
+	}
+
+	rule := models.PriceRules{
+		Label:    strings.TrimSpace(req.Label),
+		DateFrom: req.DateFrom,
+		DateTo:   req.DateTo,
+		Weekdays: req.Weekdays,
+		Priority: req.Priority,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Unit:     req.Unit,
+	}
+	msg := normalizeAmount(rule.Amount, &rule.Currency, &rule.Unit)
+	if msg == "" && rule.DateFrom != nil && rule.DateTo != nil && rule.DateTo.Before(rule.DateFrom.Time) {
+		msg = "Конец периода раньше начала"
+	}
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return models.PriceRules{}, false
+	}
+	return rule, true
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"admin-api/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type PricesAPI struct {
+	db *gorm.DB
+}
+
+func NewPricesAPI(db *gorm.DB) *PricesAPI {
+	return &PricesAPI{
+		db: db,
+	}
+}
+
+// PriceRequest — тело POST и PUT /services/{id}/prices.
+type PriceRequest struct {
+	Label      string       `json:"label"`
+	Amount     int64        `json:"amount"`
+	Currency   string       `json:"currency"`
+	Unit       string       `json:"unit"`
+	SeasonFrom *models.Date `json:"season_from"`
+	SeasonTo   *models.Date `json:"season_to"`
+}
+
+// GetPrices godoc
+// @Summary      Прайс услуги
+// @Description  Возвращает цены услуги по возрастанию суммы
+// @Tags         services
+// @Produce      json
+// @Param        id   path int true "ID услуги"
+// @Success      200 {array}  models.ServicePrices
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/prices [get]
+
+func (p *PricesAPI) GetPrices(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := p.service(w, r)
+	if !ok {
+		return
+	}
+
+	var prices []models.ServicePrices
+	if err := p.db.Scopes(orderPrices).Where("service_id = ?", serviceId).Find(&prices).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prices)
+}
+
+// CreatePrice godoc
+// @Summary      Добавить цену услуги
+// @Description  Сумма передаётся в копейках (минимальных единицах валюты). Валюта по умолчанию — RUB, единица — fixed.
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path int                   true "ID услуги"
+// @Param        price body handlers.PriceRequest true "Новая цена"
+// @Success      201 {object} models.ServicePrices
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/prices [post]
+
+func (p *PricesAPI) CreatePrice(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := p.service(w, r)
+	if !ok {
+		return
+	}
+	price, ok := decodePrice(w, r)
+	if !ok {
+		return
+	}
+
+	price.ServiceID = serviceId
+	if err := p.db.Create(&price).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(price)
+}
+
+// UpdatePrice godoc
+// @Summary      Изменить цену услуги
+// @Description  Заменяет все поля цены
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                   true "ID услуги"
+// @Param        priceId path int                   true "ID цены"
+// @Param        price   body handlers.PriceRequest true "Обновлённые данные"
+// @Success      200 {object} models.ServicePrices
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      404 {object} map[string]string "Цена не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/prices/{priceId} [put]
+
+func (p *PricesAPI) UpdatePrice(w http.ResponseWriter, r *http.Request) {
+	serviceId, priceId, ok := priceIDs(w, r)
+	if !ok {
+		return
+	}
+	price, ok := decodePrice(w, r)
+	if !ok {
+		return
+	}
+
+	result := p.db.Model(&models.ServicePrices{}).Where("id = ? AND service_id = ?", priceId, serviceId).Updates(map[string]interface{}{
+		"label":       price.Label,
+		"amount":      price.Amount,
+		"currency":    price.Currency,
+		"unit":        price.Unit,
+		"season_from": price.SeasonFrom,
+		"season_to":   price.SeasonTo,
+	})
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Цена не найдена", http.StatusNotFound)
+		return
+	}
+
+	price.ID = priceId
+	price.ServiceID = serviceId
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(price)
+}
+
+// DeletePrice godoc
+// @Summary      Удалить цену услуги
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Param        id      path int true "ID услуги"
+// @Param        priceId path int true "ID цены"
+// @Success      204 "Успешно удалено"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Цена не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/prices/{priceId} [delete]
+
+func (p *PricesAPI) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	serviceId, priceId, ok := priceIDs(w, r)
+	if !ok {
+		return
+	}
+
+	result := p.db.Where("service_id = ?", serviceId).Delete(&models.ServicePrices{}, priceId)
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Цена не найдена", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// service читает {id} и проверяет, что услуга существует.
+func (p *PricesAPI) service(w http.ResponseWriter, r *http.Request) (int, bool) {
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return 0, false
+	}
+	err := p.db.Select("id").Take(&models.Services{}, serviceId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Услуга не найдена", http.StatusNotFound)
+		return 0, false
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return 0, false
+	}
+	return serviceId, true
+}
+
+func priceIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	priceId, ok := pathID(r, "priceId")
+	if !ok {
+		http.Error(w, "Неверный ID цены", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return serviceId, priceId, true
+}
+
+// decodePrice читает и проверяет PriceRequest.
+func decodePrice(w http.ResponseWriter, r *http.Request) (models.ServicePrices, bool) {
+	var req PriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON: "+err.Error(), http.StatusBadRequest)
+		return models.ServicePrices{}, false
+	}
+
+	price := models.ServicePrices{
+		Label:      strings.TrimSpace(req.Label),
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Unit:       req.Unit,
+		SeasonFrom: req.SeasonFrom,
+		SeasonTo:   req.SeasonTo,
+	}
+	if msg := normalizePrice(&price); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return models.ServicePrices{}, false
+	}
+	return price, true
+}
+
+// normalizePrice подставляет валюту и единицу по умолчанию и возвращает
+// текст ошибки или пустую строку, если цена корректна.
+func normalizePrice(price *models.ServicePrices) string {
+	if msg := normalizeAmount(price.Amount, &price.Currency, &price.Unit); msg != "" {
+		return msg
+	}
+	if price.SeasonFrom != nil && price.SeasonTo != nil && price.SeasonTo.Before(price.SeasonFrom.Time) {
+		return "Конец сезона раньше начала"
+	}
+	return ""
+}
+
+// normalizeAmount проверяет сумму, валюту и единицу — общие поля цены и
+// правила календаря.
+func normalizeAmount(amount int64, currency, unit *string) string {
+	*currency = strings.ToUpper(strings.TrimSpace(*currency))
+	if *currency == "" {
+		*currency = models.CurrencyRUB
+	}
+	if *unit == "" {
+		*unit = models.UnitFixed
+	}
+
+	switch {
+	case amount < 0:
+		return "Сумма не может быть отрицательной"
+	case !currencyPattern.MatchString(*currency):
+		return "Валюта должна быть трёхбуквенным кодом ISO 4217, например RUB"
+	case !models.ValidUnit(*unit):
+		return "Единица должна быть одной из: fixed, night, day, hour, person"
+	}
+	return ""
+}
+
+// orderPrices сортирует прайс от дешёвых цен к дорогим.
+func orderPrices(db *gorm.DB) *gorm.DB {
+	return db.Order("amount, id")
+}
+
+// preloadPrices подгружает прайс услуг, по нему считается min_price.
+func preloadPrices(db *gorm.DB) *gorm.DB {
+	return db.Preload("PriceList", orderPrices)
+}
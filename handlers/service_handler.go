@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"admin-api/internal/pricing"
+	"admin-api/internal/query"
+	"admin-api/internal/richtext"
 	"admin-api/models"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
-	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var servicesQuery = query.Spec{
+	Sort:    []string{"id", "title", "eng"},
+	Filters: map[string]query.Kind{"eng": query.String},
+	Default: "id",
+	Key:     "id",
+}
+
 type ServicesAPI struct {
 	db *gorm.DB
 }
@@ -22,28 +33,48 @@ func NewServicesAPI(db *gorm.DB) *ServicesAPI {
 
 // GetServices godoc
 // @Summary      Получить список услуг
-// @Description  Возвращает все услуги из БД
+// @Description  Возвращает все услуги из БД вместе с прайсом (price_list) и самой низкой действующей ценой (min_price)
 // @Tags         services
 // @Security     ApiKeyAuth
 // @Produce      json
+// @Param        limit  query int    false "Сколько записей вернуть (по умолчанию 100, максимум 500)"
+// @Param        offset query int    false "Сколько записей пропустить"
+// @Param        cursor query string false "Курсор из X-Next-Cursor предыдущей страницы"
+// @Param        sort   query string false "Сортировка: id, title, eng; -поле — по убыванию"
+// @Param        eng    query string false "Фильтр по eng"
+// @Param        render query string false "html — добавить в ответ text_html с готовым HTML описания"
 // @Success      200  {array}  models.Services
+// @Header       200  {integer} X-Total-Count "Всего записей"
 // @Failure      400 {object} map[string]string "Неверный запрос"
 // @Failure      500  {object}  map[string]string
 // @Router       /services [get]
 
 func (s *ServicesAPI) GetServices(w http.ResponseWriter, r *http.Request) {
+	render, ok := renderHTML(w, r)
+	if !ok {
+		return
+	}
+
+	db, ok := withToday(w, s.db)
+	if !ok {
+		return
+	}
 	var services []models.Services
-	if err := s.db.Find(&services).Error; err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	if !findPage(w, r.URL.Query(), db, servicesQuery, &services, preloadService) {
 		return
 	}
+	if render {
+		for i := range services {
+			services[i].TextHTML = richtext.HTML(services[i].Text)
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(services)
 }
 
 // CreateService godoc
 // @Summary      Создать новую услугу (только для админа)
-// @Description  Этот эндпоинт используется только в админке.
+// @Description  Этот эндпоинт используется только в админке. Вместо текстового prices можно сразу передать price_list. Text — Markdown, HTML внутри очищается до безопасного набора тегов.
 // @Tags         admin-services
 // @Security     ApiKeyAuth
 // @Accept       json
@@ -51,6 +82,7 @@ func (s *ServicesAPI) GetServices(w http.ResponseWriter, r *http.Request) {
 // @Param        service body models.Services true "Новая услуга"
 // @Success      201 {object} models.Services
 // @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      409 {object} map[string]string "Услуга с таким eng уже существует"
 // @Failure      500 {object} map[string]string "Ошибка БД"
 // @Router       /services [post]
 
@@ -60,61 +92,264 @@ func (s *ServicesAPI) CreateService(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Неверный JSON", http.StatusBadRequest)
 		return
 	}
-	if newService.Eng == "" || newService.Title == "" || newService.Src == "" || newService.Prices == "" || newService.Text == "" {
+	if newService.Eng == "" || newService.Title == "" || (newService.Src == "" && newService.CoverID == nil) || newService.Text == "" ||
+		(newService.Prices == "" && len(newService.PriceList) == 0) {
 		http.Error(w, "Все поля обязательны", http.StatusBadRequest)
 		return
 	}
-	if err := s.db.Create(&newService).Error; err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+	if msg := validateService(newService.Eng, newService.Title, ""); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(newService)
+	text, err := richtext.Clean(newService.Text)
+	if err != nil {
+		http.Error(w, "Неверный текст: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	newService.Text = text
+	for i := range newService.PriceList {
+		newService.PriceList[i].ID = 0
+		if msg := normalizePrice(&newService.PriceList[i]); msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+	}
+	if !s.checkImages(w, newService.CoverID) {
+		return
+	}
+	if err := s.db.Omit("Cover", "Images").Create(&newService).Error; err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	s.respond(w, r, s.db.Where("id = ?", newService.ID))
+}
+
+// GetService godoc
+// @Summary      Получить услугу
+// @Tags         services
+// @Produce      json
+// @Param        id     path  int    true  "ID услуги"
+// @Param        render query string false "html — добавить в ответ text_html с готовым HTML описания"
+// @Success      200 {object} models.Services
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id} [get]
+
+func (s *ServicesAPI) GetService(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	s.respond(w, r, s.db.Where("id = ?", serviceId))
+}
+
+// GetServiceBySlug godoc
+// @Summary      Получить услугу по eng
+// @Description  Ищет услугу по полю eng — его использует сайт в адресах страниц
+// @Tags         services
+// @Produce      json
+// @Param        eng    path  string true  "eng услуги, например banquet-hall"
+// @Param        render query string false "html — добавить в ответ text_html с готовым HTML описания"
+// @Success      200 {object} models.Services
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/by-slug/{eng} [get]
+
+func (s *ServicesAPI) GetServiceBySlug(w http.ResponseWriter, r *http.Request) {
+	s.respond(w, r, s.db.Where("eng = ?", r.PathValue("eng")))
+}
+
+// ServiceRequest — тело PUT /services/{id}. Прайс меняется отдельно через
+// /services/{id}/prices, фото — через /services/{id}/images.
+type ServiceRequest struct {
+	Eng    string `json:"eng"`
+	Title  string `json:"title"`
+	Src    string `json:"src"`
+	Prices string `json:"prices"`
+	Text   string `json:"text"`
+	// CoverID меняется, только если поле передано: старый фронтенд о нём не
+	// знает, и без этого каждое сохранение отвязывало бы обложку. null
+	// отвязывает её явно.
+	CoverID OptionalID `json:"cover_id" swaggertype:"integer"`
+}
+
+// OptionalID — ID, у которого отличаются «поле не передано» (Set == false) и
+// null (Set == true, Value == nil).
+type OptionalID struct {
+	Set   bool
+	Value *int
+}
+
+func (o *OptionalID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
 }
 
 // UpdateService godoc
 // @Summary      Обновить данные услуги
-// @Description  Обновляет поля услуги по ID. Возвращает обновлённую запись.
+// @Description  Обновляет поля услуги по ID. Возвращает обновлённую запись. HTML в text очищается до безопасного набора тегов. Если cover_id не передан, обложка не меняется; null убирает обложку.
 // @Tags         services
+// @Security     ApiKeyAuth
 // @Accept       json
 // @Produce      json
 // @Param        id   path int               true "ID услуги"
-// @Param        service body models.Services true "Обновлённые данные"
+// @Param        service body ServiceRequest true "Обновлённые данные"
 // @Success      200 {object} models.Services
-// @Failure      400 {object} map[string]string "Неверный ID или JSON"
+// @Failure      400 {object} map[string]string "Неверный ID, JSON, eng или обложка не найдена"
 // @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      409 {object} map[string]string "Услуга с таким eng уже существует"
 // @Failure      500 {object} map[string]string "Ошибка БД"
 // @Router       /services/{id} [put]
 
 func (s *ServicesAPI) UpdateService(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
 
-	if !strings.HasPrefix(path, "/services/") {
-		http.Error(w, "Неверный URL", http.StatusBadRequest)
+	var req ServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	var current models.Services
+	err := s.db.Select("eng").Take(&current, serviceId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Услуга не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if msg := validateService(req.Eng, req.Title, current.Eng); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	text, err := richtext.Clean(req.Text)
+	if err != nil {
+		http.Error(w, "Неверный текст: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	idStr := path[len("/services/"):]
+	updates := map[string]interface{}{
+		"eng":    req.Eng,
+		"title":  req.Title,
+		"src":    req.Src,
+		"prices": req.Prices,
+		"text":   text,
+	}
+	if req.CoverID.Set {
+		if !s.checkImages(w, req.CoverID.Value) {
+			return
+		}
+		updates["cover_id"] = req.CoverID.Value
+	}
 
-	serviceId, err := strconv.Atoi(idStr)
-	if err != nil || serviceId <= 0 {
-		http.Error(w, "Неверный ID", http.StatusBadRequest)
+	result := s.db.Model(&models.Services{}).Where("id = ?", serviceId).Updates(updates)
+	if result.Error != nil {
+		writeServiceError(w, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Услуга не найдена", http.StatusNotFound)
 		return
 	}
 
-	var updatedService models.Services
-	if err := json.NewDecoder(r.Body).Decode(&updatedService); err != nil {
+	s.respond(w, r, s.db.Where("id = ?", serviceId))
+}
+
+// ServiceImagesRequest — тело PUT /services/{id}/images: фото галереи в
+// нужном порядке.
+type ServiceImagesRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// SetServiceImages godoc
+// @Summary      Задать фото услуги
+// @Description  Заменяет набор фото услуги: перечисленные фото галереи в указанном порядке. Пустой список убирает все фото.
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path int                           true "ID услуги"
+// @Param        images body handlers.ServiceImagesRequest true "ID фото в нужном порядке"
+// @Success      200 {object} models.Services
+// @Failure      400 {object} map[string]string "Неверный JSON или фото не найдены"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id}/images [put]
+
+func (s *ServicesAPI) SetServiceImages(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	var req ServiceImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Неверный JSON", http.StatusBadRequest)
 		return
 	}
+	ids := unique(req.IDs)
+	if len(ids) != len(req.IDs) {
+		http.Error(w, "Фото указаны дважды", http.StatusBadRequest)
+		return
+	}
+	if !s.checkImages(w, nil, ids...) {
+		return
+	}
 
-	result := s.db.Model(&models.Services{}).Where("id = ?", serviceId).Updates(map[string]interface{}{
-		"eng":    updatedService.Eng,
-		"title":  updatedService.Title,
-		"src":    updatedService.Src,
-		"prices": updatedService.Prices,
-		"text":   updatedService.Text,
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Take(&models.Services{}, serviceId).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("service_id = ?", serviceId).Delete(&models.ServiceImages{}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		images := make([]models.ServiceImages, 0, len(ids))
+		for i, id := range ids {
+			images = append(images, models.ServiceImages{ServiceID: serviceId, GalleryID: id, Position: i + 1})
+		}
+		return tx.Omit(clause.Associations).Create(&images).Error
 	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Услуга не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+
+	s.respond(w, r, s.db.Where("id = ?", serviceId))
+}
+
+// DeleteService godoc
+// @Summary      Удалить услугу
+// @Tags         services
+// @Security     ApiKeyAuth
+// @Param        id   path int true "ID услуги"
+// @Success      204 "Успешно удалено"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Услуга не найдена"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /services/{id} [delete]
+
+func (s *ServicesAPI) DeleteService(w http.ResponseWriter, r *http.Request) {
+	serviceId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	result := s.db.Delete(&models.Services{}, serviceId)
 	if result.Error != nil {
 		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
 		return
@@ -124,8 +359,114 @@ func (s *ServicesAPI) UpdateService(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respond отдаёт одну услугу, найденную запросом db.
+func (s *ServicesAPI) respond(w http.ResponseWriter, r *http.Request, db *gorm.DB) {
+	render, ok := renderHTML(w, r)
+	if !ok {
+		return
+	}
+	db, ok = withToday(w, db)
+	if !ok {
+		return
+	}
+
 	var service models.Services
-	s.db.Take(&service, serviceId)
+	err := db.Scopes(preloadService).Take(&service).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Услуга не найдена", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if render {
+		service.TextHTML = richtext.HTML(service.Text)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(service)
 }
+
+// checkImages проверяет, что обложка и фото есть в галерее, иначе отвечает 400.
+func (s *ServicesAPI) checkImages(w http.ResponseWriter, coverID *int, ids ...int) bool {
+	if coverID != nil {
+		ids = append(ids, *coverID)
+	}
+	ids = unique(ids)
+	if len(ids) == 0 {
+		return true
+	}
+	var found int64
+	if err := s.db.Model(&models.Gallery{}).Where("id IN ?", ids).Count(&found).Error; err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return false
+	}
+	if int(found) != len(ids) {
+		http.Error(w, "Некоторые фото не найдены в галерее", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// withToday передаёт в запрос сегодняшнюю дату по часовому поясу базы отдыха,
+// чтобы min_price менялась в полночь по местному времени.
+func withToday(w http.ResponseWriter, db *gorm.DB) (*gorm.DB, bool) {
+	today, err := pricing.Today(db.Session(&gorm.Session{NewDB: true}), time.Now())
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return nil, false
+	}
+	return db.Set(models.TodaySetting, today), true
+}
+
+// preloadService подгружает прайс, обложку и фото услуги.
+func preloadService(db *gorm.DB) *gorm.DB {
+	return db.Scopes(preloadPrices).
+		Preload("Cover", preloadVariants).
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Images.Gallery", preloadVariants)
+}
+
+// validateService проверяет поля, общие для создания и обновления: eng и
+// title обязательны, eng попадает в адрес страницы и пишется как slug альбома.
+// Формат eng проверяется, только если он отличается от storedEng: услуги со
+// старыми eng другого вида можно редактировать, не переименовывая.
+func validateService(eng, title, storedEng string) string {
+	if eng == "" || title == "" {
+		return "Поля eng и title обязательны"
+	}
+	if eng != storedEng && !slugPattern.MatchString(eng) {
+		return "eng может содержать только латинские буквы в нижнем регистре, цифры и дефисы"
+	}
+	return ""
+}
+
+// writeServiceError отвечает 409, если eng занят другой услугой: уникальность
+// eng проверяет сама БД. Если фото удалили из галереи между checkImages и
+// записью, внешний ключ не даст сохранить услугу — это 400, как и при проверке.
+func writeServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		http.Error(w, "Услуга с таким eng уже существует", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		http.Error(w, "Некоторые фото не найдены в галерее", http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+}
+
+// renderHTML читает параметр ?render. Сейчас поддерживается только html.
+func renderHTML(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	switch r.URL.Query().Get("render") {
+	case "":
+		return false, true
+	case "html":
+		return true, true
+	}
+	http.Error(w, "Неверный параметр render: доступно только html", http.StatusBadRequest)
+	return false, false
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"admin-api/internal/blobs"
+	"admin-api/internal/filetype"
+	"admin-api/internal/imaging"
+	"admin-api/internal/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// maxFormMemory — сколько multipart-формы держать в памяти, остальное
+// net/http сбрасывает во временные файлы.
+const maxFormMemory = 10 << 20
+
+// Uploads описывает, куда сохраняются файлы и какого размера они могут быть.
+type Uploads struct {
+	Storage storage.Storage
+	// MaxSize ограничивает весь multipart-запрос целиком.
+	MaxSize int64
+	Limits  Limits
+	// MaxPixels ограничивает ширину × высоту изображения: больше — 413 ещё
+	// до декодирования.
+	MaxPixels int64
+}
+
+// Limits — максимальный размер одного файла для каждого вида загрузок.
+type Limits struct {
+	Gallery int64
+	Docs    int64
+	Avatar  int64
+}
+
+// uploadKind определяет, какие файлы принимает эндпоинт.
+type uploadKind int
+
+const (
+	kindGallery uploadKind = iota
+	kindDocs
+	kindAvatar
+)
+
+func (u Uploads) rules(kind uploadKind) (allowed []string, maxSize int64) {
+	switch kind {
+	case kindDocs:
+		return filetype.Documents, u.Limits.Docs
+	case kindAvatar:
+		return filetype.Images, u.Limits.Avatar
+	default:
+		return filetype.Images, u.Limits.Gallery
+	}
+}
+
+// parseForm ограничивает тело запроса MaxSize и разбирает multipart-форму.
+// Слишком большой запрос превращается в ошибку 413.
+func (u Uploads) parseForm(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, u.MaxSize)
+	err := r.ParseMultipartForm(maxFormMemory)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &fileError{status: http.StatusRequestEntityTooLarge, message: "Запрос слишком большой", limit: tooLarge.Limit, err: err}
+	}
+	if err != nil {
+		return &fileError{status: http.StatusBadRequest, message: "Ошибка парсинга формы", err: err}
+	}
+	return nil
+}
+
+// saved — файл, записанный в хранилище. Для изображений Photo содержит
+// повёрнутое по EXIF фото, из которого уже удалены метаданные.
+type saved struct {
+	URL   string
+	Photo *imaging.Photo
+}
+
+// save проверяет размер и настоящий тип файла и сохраняет его в хранилище
+// внутри транзакции tx. Файл адресуется по SHA-256 содержимого: если такой
+// уже есть, новая копия не пишется, а у существующей растёт счётчик ссылок.
+// Расширение берётся из распознанного типа, а не из имени от клиента.
+// Изображения сохраняются без EXIF, чтобы координаты съёмки и сведения об
+// устройстве не попадали в /uploads/.
+func (u Uploads) save(r *http.Request, tx *gorm.DB, fileHeader *multipart.FileHeader, kind uploadKind) (saved, error) {
+	allowed, maxSize := u.rules(kind)
+	if maxSize > 0 && fileHeader.Size > maxSize {
+		return saved{}, &fileError{file: fileHeader.Filename, status: http.StatusRequestEntityTooLarge, message: "Файл слишком большой", limit: maxSize, err: errors.New("file too large")}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return saved{}, fmt.Errorf("не удалось открыть файл: %w", err)
+	}
+	defer file.Close()
+
+	mimeType, err := filetype.Detect(file, fileHeader.Size)
+	if err != nil {
+		return saved{}, fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+	if !filetype.Allowed(mimeType, allowed) {
+		return saved{}, &fileError{file: fileHeader.Filename, status: http.StatusUnsupportedMediaType, message: "Недопустимый тип файла", detected: mimeType, allowed: allowed, err: errors.New("unsupported type")}
+	}
+
+	if kind == kindDocs {
+		url, err := u.blobs().Put(r.Context(), tx, file, mimeType, filetype.Ext(mimeType))
+		if err != nil {
+			return saved{}, fmt.Errorf("ошибка записи файла: %w", err)
+		}
+		return saved{URL: url}, nil
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return saved{}, fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+	photo, err := imaging.Prepare(data, mimeType, u.MaxPixels)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return saved{}, &fileError{file: fileHeader.Filename, status: http.StatusRequestEntityTooLarge, message: "Изображение слишком большое", maxPixels: u.MaxPixels, err: err}
+	}
+	if errors.Is(err, imaging.ErrCorrupt) {
+		return saved{}, &fileError{file: fileHeader.Filename, status: http.StatusUnsupportedMediaType, message: "Повреждённое изображение", detected: mimeType, allowed: allowed, err: err}
+	}
+	if err != nil {
+		return saved{}, fmt.Errorf("не удалось обработать изображение: %w", err)
+	}
+	url, err := u.put(r.Context(), tx, photo.Data, photo.MimeType, filetype.Ext(photo.MimeType))
+	if err != nil {
+		return saved{}, err
+	}
+	return saved{URL: url, Photo: photo}, nil
+}
+
+// put сохраняет готовые данные (например, сгенерированную миниатюру) так же,
+// как save, и возвращает публичный URL.
+func (u Uploads) put(ctx context.Context, tx *gorm.DB, data []byte, contentType, ext string) (string, error) {
+	url, err := u.blobs().Put(ctx, tx, bytes.NewReader(data), contentType, ext)
+	if err != nil {
+		return "", fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	return url, nil
+}
+
+func (u Uploads) blobs() blobs.Store {
+	return blobs.Store{Storage: u.Storage}
+}
+
+// release снимает ссылку на файл внутри tx и удаляет его из хранилища, если
+// ссылка была последней. Вызывается последним шагом транзакции: если
+// хранилище недоступно, удаление строки откатится. До коммита tx держит
+// блокировку ключа, так что параллельная загрузка того же файла дождётся её
+// и запишет файл заново. Ссылки не на наше хранилище (например, внешние
+// аватары) пропускаются.
+func (u Uploads) release(ctx context.Context, tx *gorm.DB, url string) error {
+	key, err := u.blobs().Release(tx, url)
+	if err != nil || key == "" {
+		return err
+	}
+	return u.Storage.Delete(ctx, key)
+}
+
+// removeLater удаляет из хранилища ключ, освобождённый уже закоммиченной
+// транзакцией, если за это время файл не загрузили снова. Ошибка только
+// логируется: осиротевший файл подберёт команда gc-uploads.
+func (u Uploads) removeLater(ctx context.Context, db *gorm.DB, key string) {
+	if key == "" {
+		return
+	}
+	if err := u.blobs().Remove(ctx, db, key); err != nil {
+		log.Printf("не удалось удалить файл %s: %v", key, err)
+	}
+}
+
+// discard удаляет файл, записанный откатившейся транзакцией, если на него
+// не ссылается ничего другого.
+func (u Uploads) discard(ctx context.Context, db *gorm.DB, url string) {
+	if err := u.blobs().Discard(ctx, db, url); err != nil {
+		log.Printf("не удалось удалить файл %s: %v", url, err)
+	}
+}
+
+// UploadError — JSON-ответ при неудачной загрузке: что случилось и с каким файлом.
+type UploadError struct {
+	Error string `json:"error"`
+	File  string `json:"file,omitempty"`
+	// Detected и Allowed заполняются для 415: распознанный тип и допустимые типы.
+	Detected string   `json:"detected,omitempty"`
+	Allowed  []string `json:"allowed,omitempty"`
+	// Limit заполняется для 413: допустимый размер в байтах.
+	Limit int64 `json:"limit,omitempty"`
+	// MaxPixels заполняется для 413, если превышено число пикселей.
+	MaxPixels int64 `json:"max_pixels,omitempty"`
+}
+
+// fileError привязывает ошибку к конкретному файлу из пакета.
+type fileError struct {
+	file      string
+	status    int
+	message   string
+	detected  string
+	allowed   []string
+	limit     int64
+	maxPixels int64
+	err       error
+}
+
+func (e *fileError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.file, e.message, e.err)
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// batch запоминает файлы, записанные в рамках одного запроса, чтобы при
+// ошибке удалить их все разом.
+type batch struct {
+	uploads Uploads
+	db      *gorm.DB
+	r       *http.Request
+	saved   []string
+}
+
+// track добавляет в пакет файл, записанный помимо основного (миниатюры и т.п.).
+func (b *batch) track(url string) {
+	b.saved = append(b.saved, url)
+}
+
+func (b *batch) rollback() {
+	ctx := context.WithoutCancel(b.r.Context())
+	for _, url := range b.saved {
+		b.uploads.discard(ctx, b.db, url)
+	}
+}
+
+// saveBatch сохраняет все файлы пакета и для каждого вызывает create внутри
+// одной транзакции. Если хоть один файл не удался, транзакция откатывается,
+// а уже записанные файлы удаляются — пакет загружается целиком или никак.
+func (u Uploads) saveBatch(r *http.Request, db *gorm.DB, files []*multipart.FileHeader,
+	kind uploadKind, create func(tx *gorm.DB, b *batch, fileHeader *multipart.FileHeader, file saved) error) error {
+	if len(files) == 0 {
+		return &fileError{status: http.StatusBadRequest, message: "Файлы не переданы", err: errors.New("empty batch")}
+	}
+
+	b := &batch{uploads: u, db: db, r: r}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, fileHeader := range files {
+			file, err := u.save(r, tx, fileHeader, kind)
+			var fe *fileError
+			if errors.As(err, &fe) {
+				return fe
+			}
+			if err != nil {
+				return &fileError{file: fileHeader.Filename, status: http.StatusInternalServerError, message: "Ошибка записи файла", err: err}
+			}
+			b.track(file.URL)
+
+			if err := create(tx, b, fileHeader, file); err != nil {
+				var fe *fileError
+				if errors.As(err, &fe) {
+					fe.file = fileHeader.Filename
+					return fe
+				}
+				return &fileError{file: fileHeader.Filename, status: http.StatusInternalServerError, message: "Ошибка БД", err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.rollback()
+	}
+	return err
+}
+
+// writeUploadError отвечает JSON-ом с описанием ошибки загрузки.
+func writeUploadError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	body := UploadError{Error: "Ошибка загрузки"}
+
+	var fe *fileError
+	if errors.As(err, &fe) {
+		status = fe.status
+		body = UploadError{
+			Error:     fe.message,
+			File:      fe.file,
+			Detected:  fe.detected,
+			Allowed:   fe.allowed,
+			Limit:     fe.limit,
+			MaxPixels: fe.maxPixels,
+		}
+	} else {
+		log.Println("upload:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,495 @@
+package handlers
+
+import (
+	"admin-api/internal/auth"
+	"admin-api/internal/query"
+	"admin-api/internal/validate"
+	"admin-api/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var usersQuery = query.Spec{
+	Sort:    []string{"id", "name", "email", "role", "status"},
+	Filters: map[string]query.Kind{"role": query.String, "status": query.String},
+	Default: "id",
+	Key:     "id",
+}
+
+type UsersAPI struct {
+	db      *gorm.DB
+	uploads Uploads
+}
+
+func NewUsersAPI(db *gorm.DB, uploads Uploads) *UsersAPI {
+	return &UsersAPI{
+		db:      db,
+		uploads: uploads,
+	}
+}
+
+// UserRequest — тело запроса на создание и изменение пользователя.
+// Пароль не возвращается в ответах, поэтому он вынесен отдельно от models.Users.
+type UserRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Status   string `json:"status"`
+	Password string `json:"password"`
+}
+
+type UserStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// GetUsers godoc
+// @Summary      Получить список пользователей
+// @Description  Возвращает всех пользователей админки
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Produce      json
+// @Param        limit  query int    false "Сколько записей вернуть (по умолчанию 100, максимум 500)"
+// @Param        offset query int    false "Сколько записей пропустить"
+// @Param        cursor query string false "Курсор из X-Next-Cursor предыдущей страницы"
+// @Param        sort   query string false "Сортировка: id, name, email, role, status; -поле — по убыванию"
+// @Param        role   query string false "Фильтр по роли"
+// @Param        status query string false "Фильтр по статусу"
+// @Success      200  {array}  models.Users
+// @Header       200  {integer} X-Total-Count "Всего записей"
+// @Failure      400  {object}  map[string]string "Неверный параметр"
+// @Failure      500  {object}  map[string]string
+// @Router       /users [get]
+
+func (u *UsersAPI) GetUsers(w http.ResponseWriter, r *http.Request) {
+	var users []models.Users
+	list(w, r.URL.Query(), u.db, usersQuery, &users)
+}
+
+// GetUser godoc
+// @Summary      Получить пользователя
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Produce      json
+// @Param        id   path int true "ID пользователя"
+// @Success      200 {object} models.Users
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Пользователь не найден"
+// @Router       /users/{id} [get]
+
+func (u *UsersAPI) GetUser(w http.ResponseWriter, r *http.Request) {
+	userId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var user models.Users
+	if !u.take(w, &user, userId) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// CreateUser godoc
+// @Summary      Создать пользователя
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        user body UserRequest true "Новый пользователь"
+// @Success      201 {object} models.Users
+// @Failure      400 {object} ValidationError "Ошибки по полям"
+// @Failure      409 {object} map[string]string "Email уже занят"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /users [post]
+
+func (u *UsersAPI) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	errs := validateUser(&req)
+	errs.Required("password", req.Password)
+	if len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleUser
+	}
+	if req.Status == "" {
+		req.Status = models.StatusActive
+	}
+	if !auth.ValidRole(req.Role) || !validStatus(req.Status) {
+		http.Error(w, "Неизвестная роль или статус", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, "Не удалось сохранить пароль", http.StatusInternalServerError)
+		return
+	}
+	user := models.Users{
+		Name:         req.Name,
+		Email:        req.Email,
+		Role:         req.Role,
+		Status:       req.Status,
+		PasswordHash: hash,
+	}
+	if err := u.db.Create(&user).Error; err != nil {
+		writeUserError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateUser godoc
+// @Summary      Обновить пользователя
+// @Description  Обновляет имя, email, роль и статус. Пароль меняется, только если передан.
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int         true "ID пользователя"
+// @Param        user body UserRequest true "Обновлённые данные"
+// @Success      200 {object} models.Users
+// @Failure      400 {object} ValidationError "Ошибки по полям"
+// @Failure      404 {object} map[string]string "Пользователь не найден"
+// @Failure      409 {object} map[string]string "Email уже занят"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /users/{id} [put]
+
+func (u *UsersAPI) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	if errs := validateUser(&req); len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+	if !auth.ValidRole(req.Role) || !validStatus(req.Status) {
+		http.Error(w, "Неизвестная роль или статус", http.StatusBadRequest)
+		return
+	}
+	if isSelf(r, userId) && (req.Role != models.RoleAdmin || req.Status != models.StatusActive) {
+		http.Error(w, "Нельзя лишить себя прав администратора", http.StatusBadRequest)
+		return
+	}
+
+	var user models.Users
+	if !u.take(w, &user, userId) {
+		return
+	}
+
+	updates := map[string]interface{}{
+		"name":   req.Name,
+		"email":  req.Email,
+		"role":   req.Role,
+		"status": req.Status,
+	}
+	if req.Password != "" {
+		hash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			http.Error(w, "Не удалось сохранить пароль", http.StatusInternalServerError)
+			return
+		}
+		updates["password_hash"] = hash
+	}
+	if err := u.db.Model(&user).Updates(updates).Error; err != nil {
+		writeUserError(w, err)
+		return
+	}
+	if req.Status == models.StatusBlocked || req.Password != "" {
+		u.db.Where("user_id = ?", userId).Delete(&models.Sessions{})
+	}
+
+	u.db.Take(&user, userId)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// UpdateUserStatus godoc
+// @Summary      Заблокировать или разблокировать пользователя
+// @Description  Меняет статус на Active или Blocked. При блокировке все сессии пользователя завершаются.
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path int               true "ID пользователя"
+// @Param        status body UserStatusRequest true "Новый статус"
+// @Success      200 {object} models.Users
+// @Failure      400 {object} map[string]string "Неверный ID или статус"
+// @Failure      404 {object} map[string]string "Пользователь не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /users/{id}/status [put]
+
+func (u *UsersAPI) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
+	userId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UserStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	if !validStatus(req.Status) {
+		http.Error(w, "Неизвестный статус", http.StatusBadRequest)
+		return
+	}
+	if isSelf(r, userId) && req.Status != models.StatusActive {
+		http.Error(w, "Нельзя заблокировать себя", http.StatusBadRequest)
+		return
+	}
+
+	u.updateField(w, userId, "status", req.Status)
+}
+
+// UpdateUserRole godoc
+// @Summary      Изменить роль пользователя
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int             true "ID пользователя"
+// @Param        role body UserRoleRequest true "Новая роль"
+// @Success      200 {object} models.Users
+// @Failure      400 {object} map[string]string "Неверный ID или роль"
+// @Failure      404 {object} map[string]string "Пользователь не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /users/{id}/role [put]
+
+func (u *UsersAPI) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	userId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверный JSON", http.StatusBadRequest)
+		return
+	}
+	if !auth.ValidRole(req.Role) {
+		http.Error(w, "Неизвестная роль", http.StatusBadRequest)
+		return
+	}
+	if isSelf(r, userId) && req.Role != models.RoleAdmin {
+		http.Error(w, "Нельзя лишить себя прав администратора", http.StatusBadRequest)
+		return
+	}
+
+	u.updateField(w, userId, "role", req.Role)
+}
+
+// DeleteUser godoc
+// @Summary      Удалить пользователя
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Param        id   path int true "ID пользователя"
+// @Success      204 "Успешно удалено"
+// @Failure      400 {object} map[string]string "Неверный ID"
+// @Failure      404 {object} map[string]string "Пользователь не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД"
+// @Router       /users/{id} [delete]
+
+func (u *UsersAPI) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+	if isSelf(r, userId) {
+		http.Error(w, "Нельзя удалить себя", http.StatusBadRequest)
+		return
+	}
+
+	var user models.Users
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, userId).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&models.Sessions{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return u.uploads.release(r.Context(), tx, user.Avatar)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД или удаления файла", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UploadUserAvatar godoc
+// @Summary      Загрузить аватар пользователя
+// @Tags         users
+// @Security     ApiKeyAuth
+// @Accept       mpfd
+// @Produce      json
+// @Param        id   path int           true "ID пользователя"
+// @Param        file formData file      true "Изображение"
+// @Success      200 {object} models.Users
+// @Failure      400 {object} map[string]string "Неверный запрос"
+// @Failure      404 {object} map[string]string "Пользователь не найден"
+// @Failure      500 {object} map[string]string "Ошибка БД или записи файла"
+// @Router       /users/{id}/avatar [post]
+
+func (u *UsersAPI) UploadUserAvatar(w http.ResponseWriter, r *http.Request) {
+	userId, ok := pathID(r, "id")
+	if !ok {
+		http.Error(w, "Неверный ID", http.StatusBadRequest)
+		return
+	}
+
+	var user models.Users
+	if !u.take(w, &user, userId) {
+		return
+	}
+
+	if err := u.uploads.parseForm(w, r); err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	files := r.MultipartForm.File["file"]
+	if len(files) != 1 {
+		http.Error(w, "Нужно передать ровно один файл", http.StatusBadRequest)
+		return
+	}
+
+	var url, released string
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, userId).Error; err != nil {
+			return err
+		}
+		oldAvatar := user.Avatar
+		file, err := u.uploads.save(r, tx, files[0], kindAvatar)
+		if err != nil {
+			return err
+		}
+		url = file.URL
+		if err := tx.Model(&user).Update("avatar", url).Error; err != nil {
+			return err
+		}
+		released, err = u.uploads.blobs().Release(tx, oldAvatar)
+		return err
+	})
+	if err != nil && url != "" {
+		u.uploads.discard(r.Context(), u.db, url)
+	}
+	var fe *fileError
+	if errors.As(err, &fe) {
+		writeUploadError(w, err)
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	u.uploads.removeLater(r.Context(), u.db, released)
+
+	u.db.Take(&user, userId)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *UsersAPI) take(w http.ResponseWriter, user *models.Users, id int) bool {
+	err := u.db.Take(user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// validateUser проверяет имя и email, обрезает пробелы вокруг них и
+// приводит email к нижнему регистру — в таком виде его ищет вход.
+func validateUser(req *UserRequest) validate.Errors {
+	errs := validate.Errors{}
+	req.Name = strings.TrimSpace(req.Name)
+	errs.Required("name", req.Name)
+	errs.Required("email", req.Email)
+	errs.Email("email", &req.Email)
+	req.Email = auth.NormalizeEmail(req.Email)
+	return errs
+}
+
+// writeUserError отвечает 409, если email занят другим пользователем:
+// уникальность email проверяет сама БД.
+func writeUserError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		http.Error(w, "Email уже занят", http.StatusConflict)
+		return
+	}
+	http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+}
+
+func (u *UsersAPI) updateField(w http.ResponseWriter, userId int, column string, value string) {
+	result := u.db.Model(&models.Users{}).Where("id = ?", userId).Update(column, value)
+	if result.Error != nil {
+		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		return
+	}
+	if result.RowsAffected == 0 {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return
+	}
+	if column == "status" && value == models.StatusBlocked {
+		u.db.Where("user_id = ?", userId).Delete(&models.Sessions{})
+	}
+
+	var user models.Users
+	u.db.Take(&user, userId)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+func validStatus(status string) bool {
+	return status == models.StatusActive || status == models.StatusBlocked
+}
+
+func isSelf(r *http.Request, userId int) bool {
+	current := auth.UserFromContext(r.Context())
+	return current != nil && current.ID == userId
+}
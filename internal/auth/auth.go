@@ -0,0 +1,88 @@
+package auth
+
+// Package auth contains password hashing, session tokens and the request
+// context helpers used by the HTTP handlers to know who is calling.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+
+	"admin-api/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidPassword = errors.New("auth: invalid password")
+
+// HashPassword returns a bcrypt hash suitable for models.Users.PasswordHash.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// dummyHash is compared against when there is no real hash, so that a
+// failed login takes as long as a wrong password for an existing user.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// CheckPassword compares a stored hash with a plain-text password. Users
+// without a hash (e.g. imported without a password) can never log in. Pass
+// an empty hash for an unknown user too: the comparison still costs a full
+// bcrypt round, so response time doesn't reveal which emails exist.
+func CheckPassword(hash, password string) error {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return ErrInvalidPassword
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// NormalizeEmail returns the form in which emails are stored and looked up:
+// trimmed and lower-cased, so "Admin@Example.com" and "admin@example.com"
+// are the same account for login and for the unique index.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NewToken generates a random session token and returns it together with the
+// hash that should be stored in the database.
+func NewToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the value stored in models.Sessions.TokenHash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type contextKey struct{}
+
+// WithUser stores the authenticated user in the request context.
+func WithUser(ctx context.Context, user *models.Users) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user or nil.
+func UserFromContext(ctx context.Context) *models.Users {
+	user, _ := ctx.Value(contextKey{}).(*models.Users)
+	return user
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+// EnsureAdmin creates an active administrator with the given credentials if
+// no user with this email exists yet. It is used on startup so that a fresh
+// database is not left without anyone able to log in. A user with this email
+// but without a password (e.g. imported by the seed command) can't log in
+// either, so it is given the password and made an active administrator.
+func EnsureAdmin(db *gorm.DB, email, password string) (created bool, err error) {
+	email = NormalizeEmail(email)
+	if email == "" || password == "" {
+		return false, nil
+	}
+
+	var existing models.Users
+	err = db.Where("email = ?", email).Take(&existing).Error
+	if err == nil && existing.PasswordHash != "" {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	if existing.ID != 0 {
+		return true, db.Model(&existing).Updates(map[string]interface{}{
+			"role":          models.RoleAdmin,
+			"status":        models.StatusActive,
+			"password_hash": hash,
+		}).Error
+	}
+	admin := models.Users{
+		Name:         "admin",
+		Email:        email,
+		Role:         models.RoleAdmin,
+		Status:       models.StatusActive,
+		PasswordHash: hash,
+	}
+	if err := db.Create(&admin).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import "admin-api/models"
+
+// Permission is an action a role may perform. Routes declare the permission
+// they need in main.go, handlers themselves know nothing about roles.
+type Permission string
+
+const (
+	PermServicesWrite  Permission = "services:write"
+	PermServicesDelete Permission = "services:delete"
+	PermGalleryWrite   Permission = "gallery:write"
+	PermGalleryDelete  Permission = "gallery:delete"
+	PermDocsWrite      Permission = "docs:write"
+	PermDocsDelete     Permission = "docs:delete"
+	PermContactsWrite  Permission = "contacts:write"
+	PermUsersManage    Permission = "users:manage"
+)
+
+var rolePermissions = map[string][]Permission{
+	models.RoleAdmin: {
+		PermServicesWrite,
+		PermServicesDelete,
+		PermGalleryWrite,
+		PermGalleryDelete,
+		PermDocsWrite,
+		PermDocsDelete,
+		PermContactsWrite,
+		PermUsersManage,
+	},
+	models.RoleEditor: {
+		PermServicesWrite,
+		PermGalleryWrite,
+		PermGalleryDelete,
+		PermDocsWrite,
+	},
+	models.RoleUser: {},
+}
+
+// Can reports whether the user's role grants the permission. Blocked users
+// and unknown roles get nothing.
+func Can(user *models.Users, perm Permission) bool {
+	if user == nil || user.Status == models.StatusBlocked {
+		return false
+	}
+	for _, p := range rolePermissions[user.Role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
@@ -0,0 +1,144 @@
+package blobs
+
+// Package blobs stores uploads by their SHA-256 so that the same content is
+// kept once no matter how many gallery items or documents use it. Every
+// reference is counted in models.Files; the blob is removed from storage only
+// when the last reference is released.
+//
+// Writing, counting and deleting a blob all happen under a transaction-level
+// advisory lock on its key (the hash plus extension), so an upload of the
+// same content can't re-insert the files row between the release of the
+// last reference and the removal of the blob.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+
+	"admin-api/internal/storage"
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+type Store struct {
+	Storage storage.Storage
+}
+
+// Put stores the content of r (or reuses an identical blob) and adds one
+// reference to it inside tx. Returns the public URL of the blob.
+func (s Store) Put(ctx context.Context, tx *gorm.DB, r io.Reader, mimeType, ext string) (string, error) {
+	tmp, err := os.CreateTemp("", "blob-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		return "", err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	key := hash + ext
+	if err := lock(tx, key); err != nil {
+		return "", err
+	}
+
+	var existing models.Files
+	err = tx.Where("hash = ?", hash).Take(&existing).Error
+	if err == nil {
+		if err := tx.Model(&existing).Update("ref_count", gorm.Expr("ref_count + 1")).Error; err != nil {
+			return "", err
+		}
+		return s.Storage.URL(existing.Key), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	if err := s.Storage.Put(ctx, key, tmp, size, mimeType); err != nil {
+		return "", err
+	}
+
+	file := models.Files{Hash: hash, Size: size, MimeType: mimeType, Key: key, RefCount: 1}
+	if err := tx.Create(&file).Error; err != nil {
+		return "", err
+	}
+	return s.Storage.URL(key), nil
+}
+
+// Release removes one reference to the blob behind url inside tx. It returns
+// the storage key to delete once the transaction commits, or "" while the
+// blob is still used elsewhere. Files uploaded before deduplication have no
+// models.Files row: other rows may still point to them, so they are left to
+// the gc-uploads command.
+func (s Store) Release(tx *gorm.DB, url string) (string, error) {
+	key, ok := storage.KeyOf(s.Storage, url)
+	if !ok {
+		return "", nil
+	}
+	if err := lock(tx, key); err != nil {
+		return "", err
+	}
+
+	var file models.Files
+	err := tx.Where("key = ?", key).Take(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if file.RefCount > 1 {
+		return "", tx.Model(&file).Update("ref_count", gorm.Expr("ref_count - 1")).Error
+	}
+	return key, tx.Delete(&file).Error
+}
+
+// Remove deletes the blob stored under key unless a files row counts it. It
+// is called after the transaction that released the last reference has
+// committed: by then another upload of the same content may have stored the
+// blob again, and the lock makes the check and the deletion atomic with
+// respect to it.
+func (s Store) Remove(ctx context.Context, db *gorm.DB, key string) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lock(tx, key); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.Files{}).Where("key = ?", key).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return s.Storage.Delete(ctx, key)
+	})
+}
+
+// Discard deletes a blob written by a transaction that was rolled back, unless
+// some committed row still counts it. A transaction that is writing the same
+// blob right now holds the lock, so Discard waits for it and then sees its row.
+func (s Store) Discard(ctx context.Context, db *gorm.DB, url string) error {
+	key, ok := storage.KeyOf(s.Storage, url)
+	if !ok {
+		return nil
+	}
+	return s.Remove(ctx, db, key)
+}
+
+// lock takes a transaction-level advisory lock on key; it is released on
+// commit or rollback.
+func lock(tx *gorm.DB, key string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", key).Error
+}
@@ -0,0 +1,207 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"admin-api/internal/dbtest"
+	"admin-api/internal/storage"
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+func newStore(t *testing.T) (Store, *gorm.DB, string) {
+	t.Helper()
+	db := dbtest.Open(t, &models.Files{})
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir, "/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Store{Storage: local}, db, dir
+}
+
+func put(t *testing.T, s Store, db *gorm.DB, content string) string {
+	t.Helper()
+	var url string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		url, err = s.Put(context.Background(), tx, strings.NewReader(content), "text/plain", ".txt")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return url
+}
+
+func release(t *testing.T, s Store, db *gorm.DB, url string) string {
+	t.Helper()
+	var key string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		key, err = s.Release(tx, url)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func refCount(t *testing.T, db *gorm.DB, url string) int {
+	t.Helper()
+	var file models.Files
+	err := db.Where("key = ?", strings.TrimPrefix(url, "/uploads/")).Take(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.RefCount
+}
+
+func exists(dir, url string) bool {
+	_, err := os.Stat(filepath.Join(dir, strings.TrimPrefix(url, "/uploads/")))
+	return err == nil
+}
+
+func TestRefCounting(t *testing.T) {
+	s, db, dir := newStore(t)
+	ctx := context.Background()
+
+	first := put(t, s, db, "same content")
+	second := put(t, s, db, "same content")
+	other := put(t, s, db, "other content")
+	if first != second || first == other {
+		t.Fatalf("urls = %s, %s, %s; want identical content deduplicated", first, second, other)
+	}
+	if n := refCount(t, db, first); n != 2 {
+		t.Fatalf("ref_count = %d, want 2", n)
+	}
+
+	if key := release(t, s, db, first); key != "" {
+		t.Fatalf("Release with another reference left = %q, want \"\"", key)
+	}
+	if n := refCount(t, db, first); n != 1 {
+		t.Fatalf("ref_count = %d, want 1", n)
+	}
+
+	key := release(t, s, db, second)
+	if key == "" || refCount(t, db, first) != 0 {
+		t.Fatalf("last Release = %q, want the key and no files row", key)
+	}
+	if err := s.Remove(ctx, db, key); err != nil {
+		t.Fatal(err)
+	}
+	if exists(dir, first) || !exists(dir, other) {
+		t.Error("Remove must delete only the released blob")
+	}
+}
+
+func TestRemoveKeepsReuploadedBlob(t *testing.T) {
+	s, db, dir := newStore(t)
+
+	url := put(t, s, db, "photo")
+	key := release(t, s, db, url)
+	// The same picture is uploaded again between Release committing and Remove.
+	put(t, s, db, "photo")
+
+	if err := s.Remove(context.Background(), db, key); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(dir, url) {
+		t.Error("Remove deleted a blob that is referenced again")
+	}
+}
+
+func TestReleaseKeepsLegacyFiles(t *testing.T) {
+	s, db, dir := newStore(t)
+	if err := os.WriteFile(filepath.Join(dir, "upload_1700000000_abc.jpg"), []byte("legacy"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if key := release(t, s, db, "/uploads/upload_1700000000_abc.jpg"); key != "" {
+		t.Errorf("Release of a legacy file = %q, want \"\" (left to gc-uploads)", key)
+	}
+	if key := release(t, s, db, "https://example.com/avatar.jpg"); key != "" {
+		t.Errorf("Release of an external link = %q", key)
+	}
+}
+
+func TestDiscardAfterRollback(t *testing.T) {
+	s, db, dir := newStore(t)
+	ctx := context.Background()
+
+	kept := put(t, s, db, "kept")
+	var written []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, content := range []string{"kept", "new"} {
+			url, err := s.Put(ctx, tx, strings.NewReader(content), "text/plain", ".txt")
+			if err != nil {
+				return err
+			}
+			written = append(written, url)
+		}
+		return errors.New("rollback")
+	})
+	if err == nil || len(written) != 2 {
+		t.Fatalf("transaction = %v, written %v", err, written)
+	}
+	for _, url := range written {
+		if err := s.Discard(ctx, db, url); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !exists(dir, kept) || refCount(t, db, kept) != 1 {
+		t.Error("Discard removed a blob counted by a committed row")
+	}
+	if exists(dir, written[1]) {
+		t.Error("Discard left the blob of the rolled back transaction")
+	}
+}
+
+func TestDiscardWaitsForConcurrentPut(t *testing.T) {
+	s, db, dir := newStore(t)
+	ctx := context.Background()
+
+	// The first upload rolled back, but its blob is not discarded yet.
+	var url string
+	db.Transaction(func(tx *gorm.DB) error {
+		url, _ = s.Put(ctx, tx, strings.NewReader("race"), "text/plain", ".txt")
+		return errors.New("rollback")
+	})
+
+	// A second upload of the same content has written it and not committed.
+	tx := db.Begin()
+	if _, err := s.Put(ctx, tx, strings.NewReader("race"), "text/plain", ".txt"); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.Discard(ctx, db, url) }()
+	select {
+	case err := <-done:
+		tx.Rollback()
+		t.Fatalf("Discard didn't wait for the open transaction: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !exists(dir, url) || refCount(t, db, url) != 1 {
+		t.Error("Discard deleted a blob committed by a concurrent upload")
+	}
+}
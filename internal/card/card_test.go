@@ -0,0 +1,178 @@
+package card
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"admin-api/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func ptr(f float64) *float64 { return &f }
+
+func day(s string) models.Date {
+	d, err := models.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// now is the moment the cards are rendered at; exceptions before it are
+// left out of the markup.
+var now = time.Date(2026, 10, 17, 22, 30, 0, 0, time.UTC)
+
+var locations = []struct {
+	name string
+	loc  models.Locations
+}{
+	{
+		name: "full",
+		loc: models.Locations{
+			Name:      "База отдыха «Морская; Волна», корпус 2",
+			Address:   "Краснодарский край, г. Сочи, Лазаревский район, пос. Лазаревское, ул. Морская, д. 1, корп. 2",
+			Latitude:  ptr(43.9087),
+			Longitude: ptr(39.3326),
+			Phones:    models.StringList{"+79001234567", "8 (862) 270-00-00", "доб. 105"},
+			Emails:    models.StringList{"info@example.com", "booking@example.com"},
+			Website:   "https://example.com/",
+			WorkSchedule: "Пн-Пт 9:00-18:00\n" +
+				"Сб-Вс 10:00-16:00, обед 13:00-14:00; в праздники — по записи",
+			OpeningHours: &models.OpeningHours{
+				Timezone: "Europe/Moscow",
+				Weekly: map[string][]models.Interval{
+					"mon": {{Open: "09:00", Close: "18:00"}},
+					"tue": {{Open: "09:00", Close: "18:00"}},
+					"wed": {{Open: "09:00", Close: "18:00"}},
+					"thu": {{Open: "09:00", Close: "18:00"}},
+					"fri": {{Open: "09:00", Close: "18:00"}},
+					"sat": {{Open: "10:00", Close: "13:00"}, {Open: "14:00", Close: "16:00"}},
+					"sun": {{Open: "10:00", Close: "13:00"}, {Open: "14:00", Close: "16:00"}},
+				},
+				Exceptions: []models.HoursException{
+					// Already past in Moscow at now.
+					{Date: day("2026-10-17"), Closed: true},
+					{Date: day("2026-10-18"), Intervals: []models.Interval{{Open: "10:00", Close: "12:00"}, {Open: "15:00", Close: "17:00"}}},
+					{Date: day("2026-12-31"), Closed: true, Note: "Новый год"},
+				},
+			},
+			SocialMediaVK:     "https://vk.com/resort",
+			SocialMediaYa:     " ",
+			SocialMediaTwoGis: "https://2gis.ru/sochi/firm/1",
+		},
+	},
+	{
+		name: "split_week",
+		loc: models.Locations{
+			Name: "Баня",
+			OpeningHours: &models.OpeningHours{
+				Timezone: "Europe/Moscow",
+				Weekly: map[string][]models.Interval{
+					"mon": {{Open: "12:00", Close: "24:00"}},
+					"wed": {{Open: "12:00", Close: "24:00"}},
+					"thu": {{Open: "12:00", Close: "24:00"}},
+					"fri": {{Open: "18:00", Close: "02:00"}},
+					"sat": {{Open: "18:00", Close: "02:00"}},
+					"sun": {},
+				},
+			},
+		},
+	},
+	{
+		name: "minimal",
+		loc:  models.Locations{Name: "Основная площадка"},
+	},
+}
+
+func TestJSONLD(t *testing.T) {
+	for _, tt := range locations {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(JSONLD(tt.loc, now), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.name+".jsonld", append(got, '\n'))
+		})
+	}
+}
+
+func TestVCard(t *testing.T) {
+	for _, tt := range locations {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VCard(tt.loc)
+			for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+				if len(line) > maxLine {
+					t.Errorf("line of %d octets: %q", len(line), line)
+				}
+			}
+			golden(t, tt.name+".vcf", []byte(got))
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []string{
+		"",
+		strings.Repeat("a", maxLine),
+		strings.Repeat("a", maxLine+1),
+		strings.Repeat("a", 3*maxLine),
+		"NOTE:" + strings.Repeat("я", 100),
+		"NOTE:" + strings.Repeat("a", 72) + "€€€" + strings.Repeat("b", 80),
+	}
+	for _, s := range tests {
+		folded := fold(s)
+		for _, line := range strings.Split(folded, "\r\n") {
+			if len(line) > maxLine {
+				t.Errorf("fold(%d octets): line of %d octets", len(s), len(line))
+			}
+			if !utf8.ValidString(line) {
+				t.Errorf("fold(%d octets) cut a UTF-8 sequence: %q", len(s), line)
+			}
+		}
+		if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != s {
+			t.Errorf("unfold(fold(s)) = %q, want %q", unfolded, s)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Морская, 1", `Морская\, 1`},
+		{"a;b", `a\;b`},
+		{`C:\путь`, `C:\\путь`},
+		{"строка 1\r\nстрока 2\nстрока 3\rстрока 4", `строка 1\nстрока 2\nстрока 3\nстрока 4`},
+		{`\,`, `\\\,`},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// golden compares got with testdata/name, or rewrites the file with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file, rerun with -update if the change is intended:\n got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package card
+
+// Package card exports a location's contacts as a business card: schema.org
+// JSON-LD markup for search engines and a vCard for address books.
+
+import (
+	"strings"
+	"time"
+
+	"admin-api/models"
+)
+
+// BusinessType is the schema.org type of the markup, a LodgingBusiness
+// subtype that fits a holiday resort.
+const BusinessType = "Resort"
+
+// Business is the schema.org LocalBusiness markup of a location.
+type Business struct {
+	Context      string         `json:"@context"`
+	Type         string         `json:"@type"`
+	Name         string         `json:"name,omitempty"`
+	URL          string         `json:"url,omitempty"`
+	Telephone    string         `json:"telephone,omitempty"`
+	Email        string         `json:"email,omitempty"`
+	Address      *PostalAddress `json:"address,omitempty"`
+	Geo          *Geo           `json:"geo,omitempty"`
+	OpeningHours []string       `json:"openingHours,omitempty"`
+	// SpecialOpeningHours lists upcoming holidays and short days.
+	SpecialOpeningHours []OpeningHoursSpecification `json:"specialOpeningHoursSpecification,omitempty"`
+	SameAs              []string                    `json:"sameAs,omitempty"`
+}
+
+// PostalAddress holds the address as one line: locations don't split it
+// into city, street and postcode.
+type PostalAddress struct {
+	Type          string `json:"@type"`
+	StreetAddress string `json:"streetAddress"`
+}
+
+type Geo struct {
+	Type      string  `json:"@type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// OpeningHoursSpecification is a schedule for a single date. A closed day
+// opens and closes at 00:00, as search engines expect.
+type OpeningHoursSpecification struct {
+	Type         string `json:"@type"`
+	ValidFrom    string `json:"validFrom"`
+	ValidThrough string `json:"validThrough"`
+	Opens        string `json:"opens"`
+	Closes       string `json:"closes"`
+}
+
+// schemaDays are the schema.org day abbreviations for WeekdayKeys.
+var schemaDays = map[string]string{
+	"mon": "Mo", "tue": "Tu", "wed": "We", "thu": "Th", "fri": "Fr", "sat": "Sa", "sun": "Su",
+}
+
+// weekOrder lists days Monday first so that ranges read "Mo-Fr".
+var weekOrder = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// JSONLD builds the markup of loc. Exceptions to the opening hours that are
+// already in the past at now are left out.
+func JSONLD(loc models.Locations, now time.Time) Business {
+	b := Business{
+		Context:   "https://schema.org",
+		Type:      BusinessType,
+		Name:      loc.Name,
+		URL:       loc.Website,
+		Telephone: first(loc.Phones),
+		Email:     first(loc.Emails),
+		SameAs:    socials(loc),
+	}
+	if loc.Address != "" {
+		b.Address = &PostalAddress{Type: "PostalAddress", StreetAddress: loc.Address}
+	}
+	if loc.Latitude != nil && loc.Longitude != nil {
+		b.Geo = &Geo{Type: "GeoCoordinates", Latitude: *loc.Latitude, Longitude: *loc.Longitude}
+	}
+	if loc.OpeningHours != nil {
+		b.OpeningHours = openingHours(loc.OpeningHours.Weekly)
+		b.SpecialOpeningHours = specialHours(loc.OpeningHours, now)
+	}
+	return b
+}
+
+// openingHours renders the weekly schedule as "Mo-Fr 09:00-18:00" strings,
+// joining consecutive days with the same intervals.
+func openingHours(weekly map[string][]models.Interval) []string {
+	var out []string
+	for i := 0; i < len(weekOrder); {
+		day := weekOrder[i]
+		j := i + 1
+		for j < len(weekOrder) && sameIntervals(weekly[day], weekly[weekOrder[j]]) {
+			j++
+		}
+		if len(weekly[day]) > 0 {
+			days := schemaDays[day]
+			if j-i > 1 {
+				days += "-" + schemaDays[weekOrder[j-1]]
+			}
+			for _, interval := range weekly[day] {
+				out = append(out, days+" "+interval.Open+"-"+interval.Close)
+			}
+		}
+		i = j
+	}
+	return out
+}
+
+func sameIntervals(a, b []models.Interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// specialHours converts the exceptions dated today or later, in the
+// schedule's time zone.
+func specialHours(h *models.OpeningHours, now time.Time) []OpeningHoursSpecification {
+	if tz, err := time.LoadLocation(h.Timezone); err == nil {
+		now = now.In(tz)
+	}
+	today := now.Format(models.DateLayout)
+
+	var out []OpeningHoursSpecification
+	for _, exception := range h.Exceptions {
+		date := exception.Date.Format(models.DateLayout)
+		if date < today {
+			continue
+		}
+		spec := OpeningHoursSpecification{
+			Type:         "OpeningHoursSpecification",
+			ValidFrom:    date,
+			ValidThrough: date,
+			Opens:        "00:00",
+			Closes:       "00:00",
+		}
+		if exception.Closed || len(exception.Intervals) == 0 {
+			out = append(out, spec)
+			continue
+		}
+		for _, interval := range exception.Intervals {
+			spec.Opens, spec.Closes = interval.Open, interval.Close
+			out = append(out, spec)
+		}
+	}
+	return out
+}
+
+// socials returns the non-empty social links.
+func socials(loc models.Locations) []string {
+	var out []string
+	for _, link := range []string{loc.SocialMediaVK, loc.SocialMediaYa, loc.SocialMediaTwoGis} {
+		if link = strings.TrimSpace(link); link != "" {
+			out = append(out, link)
+		}
+	}
+	return out
+}
+
+func first(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+	return list[0]
+}
@@ -0,0 +1,105 @@
+package card
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"admin-api/internal/validate"
+	"admin-api/models"
+)
+
+// maxLine is the line length limit of RFC 6350, in octets, before folding.
+const maxLine = 75
+
+// VCard renders loc as a vCard 4.0 organization card. The first phone and
+// email are marked preferred.
+func VCard(loc models.Locations) string {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(fold(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCARD")
+	line("VERSION:4.0")
+	line("KIND:org")
+	line("FN:" + escape(loc.Name))
+	line("ORG:" + escape(loc.Name))
+	if loc.Address != "" {
+		// The whole address goes into the street component.
+		line("ADR;TYPE=work:;;" + escape(loc.Address) + ";;;;")
+	}
+	for i, phone := range loc.Phones {
+		if validated, err := validate.Phone(phone); err == nil {
+			line("TEL;VALUE=uri;TYPE=work,voice" + pref(i) + ":tel:" + validated)
+		} else {
+			// Numbers saved before validation may not fit a tel: URI.
+			line("TEL;VALUE=text;TYPE=work,voice" + pref(i) + ":" + escape(phone))
+		}
+	}
+	for i, email := range loc.Emails {
+		line("EMAIL;TYPE=work" + pref(i) + ":" + escape(email))
+	}
+	if loc.Latitude != nil && loc.Longitude != nil {
+		line("GEO:geo:" + coordinate(*loc.Latitude) + "," + coordinate(*loc.Longitude))
+	}
+	if loc.Website != "" {
+		line("URL;TYPE=work:" + loc.Website)
+	}
+	for _, link := range socials(loc) {
+		line("URL:" + link)
+	}
+	if loc.WorkSchedule != "" {
+		line("NOTE:" + escape(loc.WorkSchedule))
+	}
+	line("END:VCARD")
+	return b.String()
+}
+
+func pref(i int) string {
+	if i == 0 {
+		return ";PREF=1"
+	}
+	return ""
+}
+
+func coordinate(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escape escapes a text value: backslashes, commas, semicolons and line
+// breaks.
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		",", `\,`,
+		";", `\;`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// fold splits a content line longer than maxLine octets into continuation
+// lines that start with a space, never cutting a UTF-8 sequence.
+func fold(s string) string {
+	if len(s) <= maxLine {
+		return s
+	}
+	var b strings.Builder
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the next line.
+		limit = maxLine - 1
+	}
+	b.WriteString(s)
+	return b.String()
+}
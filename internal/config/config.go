@@ -0,0 +1,301 @@
+package config
+
+// Package config loads typed application settings from an optional YAML file
+// and environment variables (the latter win), and validates them on startup.
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	DB      DBConfig      `yaml:"db"`
+	HTTP    HTTPConfig    `yaml:"http"`
+	CORS    CORSConfig    `yaml:"cors"`
+	Uploads UploadsConfig `yaml:"uploads"`
+	Auth    AuthConfig    `yaml:"auth"`
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+type HTTPConfig struct {
+	// Addr is the listen address, e.g. ":8080".
+	Addr string `yaml:"addr"`
+	// PublicURL is the externally visible base URL, used for Swagger.
+	PublicURL string `yaml:"public_url"`
+}
+
+type CORSConfig struct {
+	Origins []string `yaml:"origins"`
+}
+
+type UploadsConfig struct {
+	// Backend is "local" (files in Dir) or "s3".
+	Backend string `yaml:"backend"`
+	Dir     string `yaml:"dir"`
+	// MaxSize limits a whole multipart request, Limits — a single file.
+	MaxSize ByteSize     `yaml:"max_size"`
+	Limits  UploadLimits `yaml:"limits"`
+	// MaxPixels caps width × height of uploaded images; larger ones are
+	// rejected before decoding.
+	MaxPixels int64    `yaml:"max_pixels"`
+	S3        S3Config `yaml:"s3"`
+}
+
+type UploadLimits struct {
+	Gallery ByteSize `yaml:"gallery"`
+	Docs    ByteSize `yaml:"docs"`
+	Avatar  ByteSize `yaml:"avatar"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	UseSSL    bool   `yaml:"use_ssl"`
+	PublicURL string `yaml:"public_url"`
+}
+
+type AuthConfig struct {
+	SessionTTL    Duration `yaml:"session_ttl"`
+	AdminEmail    string   `yaml:"admin_email"`
+	AdminPassword string   `yaml:"admin_password"`
+}
+
+// DSN returns the Postgres connection string for gorm.io/driver/postgres.
+// Values are quoted, so a password may contain spaces, quotes and backslashes.
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		quoteDSN(c.Host), quoteDSN(c.User), quoteDSN(c.Password), quoteDSN(c.Name), c.Port, quoteDSN(c.SSLMode))
+}
+
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// quoteDSN quotes a keyword/value connection string value the way libpq
+// expects: in single quotes, with ' and \ escaped by a backslash.
+func quoteDSN(v string) string {
+	return "'" + dsnEscaper.Replace(v) + "'"
+}
+
+func defaults() Config {
+	return Config{
+		DB: DBConfig{
+			Host:    "localhost",
+			Port:    5432,
+			User:    "admin",
+			Name:    "admin_api",
+			SSLMode: "disable",
+		},
+		HTTP: HTTPConfig{
+			Addr:      ":8080",
+			PublicURL: "http://localhost:8080",
+		},
+		CORS: CORSConfig{
+			Origins: []string{"http://localhost:3000"},
+		},
+		Uploads: UploadsConfig{
+			Backend: "local",
+			Dir:     "uploads",
+			MaxSize: 200 << 20,
+			Limits: UploadLimits{
+				Gallery: 20 << 20,
+				Docs:    20 << 20,
+				Avatar:  2 << 20,
+			},
+			MaxPixels: 50_000_000,
+		},
+		Auth: AuthConfig{
+			SessionTTL: Duration(24 * time.Hour),
+		},
+	}
+}
+
+// Load reads settings in order: defaults, the YAML file at path (skipped when
+// path is empty or the file does not exist and was not required), environment.
+func Load(path string, required bool) (Config, error) {
+	cfg := defaults()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("%s: %w", path, err)
+			}
+		case errors.Is(err, os.ErrNotExist) && !required:
+		default:
+			return cfg, err
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func applyEnv(cfg *Config) error {
+	str := func(name string, dst *string) {
+		if v, ok := os.LookupEnv(name); ok {
+			*dst = v
+		}
+	}
+	str("DB_HOST", &cfg.DB.Host)
+	str("DB_USER", &cfg.DB.User)
+	str("DB_PASSWORD", &cfg.DB.Password)
+	str("DB_NAME", &cfg.DB.Name)
+	str("DB_SSLMODE", &cfg.DB.SSLMode)
+	str("HTTP_ADDR", &cfg.HTTP.Addr)
+	str("PUBLIC_URL", &cfg.HTTP.PublicURL)
+	str("UPLOAD_BACKEND", &cfg.Uploads.Backend)
+	str("UPLOAD_DIR", &cfg.Uploads.Dir)
+	str("S3_ENDPOINT", &cfg.Uploads.S3.Endpoint)
+	str("S3_REGION", &cfg.Uploads.S3.Region)
+	str("S3_BUCKET", &cfg.Uploads.S3.Bucket)
+	str("S3_ACCESS_KEY", &cfg.Uploads.S3.AccessKey)
+	str("S3_SECRET_KEY", &cfg.Uploads.S3.SecretKey)
+	str("S3_PUBLIC_URL", &cfg.Uploads.S3.PublicURL)
+	str("ADMIN_EMAIL", &cfg.Auth.AdminEmail)
+	str("ADMIN_PASSWORD", &cfg.Auth.AdminPassword)
+
+	if v, ok := os.LookupEnv("DB_PORT"); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("DB_PORT: %w", err)
+		}
+		cfg.DB.Port = port
+	}
+	if v, ok := os.LookupEnv("UPLOAD_MAX_PIXELS"); ok {
+		pixels, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("UPLOAD_MAX_PIXELS: %w", err)
+		}
+		cfg.Uploads.MaxPixels = pixels
+	}
+	if v, ok := os.LookupEnv("S3_USE_SSL"); ok {
+		useSSL, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("S3_USE_SSL: %w", err)
+		}
+		cfg.Uploads.S3.UseSSL = useSSL
+	}
+	if v, ok := os.LookupEnv("CORS_ORIGINS"); ok {
+		cfg.CORS.Origins = splitList(v)
+	}
+	sizes := []struct {
+		name string
+		dst  *ByteSize
+	}{
+		{"UPLOAD_MAX_SIZE", &cfg.Uploads.MaxSize},
+		{"UPLOAD_LIMIT_GALLERY", &cfg.Uploads.Limits.Gallery},
+		{"UPLOAD_LIMIT_DOCS", &cfg.Uploads.Limits.Docs},
+		{"UPLOAD_LIMIT_AVATAR", &cfg.Uploads.Limits.Avatar},
+	}
+	for _, size := range sizes {
+		if v, ok := os.LookupEnv(size.name); ok {
+			parsed, err := ParseByteSize(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", size.name, err)
+			}
+			*size.dst = parsed
+		}
+	}
+	if v, ok := os.LookupEnv("SESSION_TTL"); ok {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("SESSION_TTL: %w", err)
+		}
+		cfg.Auth.SessionTTL = Duration(ttl)
+	}
+	return nil
+}
+
+// Validate reports all invalid settings at once.
+func (c Config) Validate() error {
+	var errs []error
+	if c.DB.Host == "" || c.DB.User == "" || c.DB.Name == "" {
+		errs = append(errs, errors.New("db: host, user и name обязательны"))
+	}
+	if c.DB.Password == "" {
+		errs = append(errs, errors.New("db: не задан пароль (db.password или DB_PASSWORD)"))
+	}
+	if c.DB.Port <= 0 || c.DB.Port > 65535 {
+		errs = append(errs, fmt.Errorf("db: неверный порт %d", c.DB.Port))
+	}
+	if c.HTTP.Addr == "" {
+		errs = append(errs, errors.New("http: addr обязателен"))
+	}
+	if u, err := url.Parse(c.HTTP.PublicURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("http: неверный public_url %q", c.HTTP.PublicURL))
+	}
+	for _, origin := range c.CORS.Origins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Errorf("cors: неверный origin %q", origin))
+		}
+	}
+	switch c.Uploads.Backend {
+	case "local":
+		if c.Uploads.Dir == "" {
+			errs = append(errs, errors.New("uploads: dir обязателен"))
+		}
+	case "s3":
+		s3 := c.Uploads.S3
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			errs = append(errs, errors.New("uploads.s3: endpoint, bucket, access_key и secret_key обязательны"))
+		}
+		if s3.PublicURL != "" && !strings.HasSuffix(s3.PublicURL, "/") {
+			errs = append(errs, errors.New("uploads.s3: public_url должен заканчиваться на /"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("uploads: неизвестный backend %q (local или s3)", c.Uploads.Backend))
+	}
+	if c.Uploads.MaxSize <= 0 {
+		errs = append(errs, errors.New("uploads: max_size должен быть больше нуля"))
+	}
+	limits := c.Uploads.Limits
+	if limits.Gallery <= 0 || limits.Docs <= 0 || limits.Avatar <= 0 {
+		errs = append(errs, errors.New("uploads.limits: gallery, docs и avatar должны быть больше нуля"))
+	}
+	if limits.Gallery > c.Uploads.MaxSize || limits.Docs > c.Uploads.MaxSize || limits.Avatar > c.Uploads.MaxSize {
+		errs = append(errs, errors.New("uploads.limits: лимит файла не может быть больше max_size"))
+	}
+	if c.Uploads.MaxPixels <= 0 {
+		errs = append(errs, errors.New("uploads: max_pixels должен быть больше нуля"))
+	}
+	if c.Auth.SessionTTL <= 0 {
+		errs = append(errs, errors.New("auth: session_ttl должен быть больше нуля"))
+	}
+	if (c.Auth.AdminEmail == "") != (c.Auth.AdminPassword == "") {
+		errs = append(errs, errors.New("auth: admin_email и admin_password задаются вместе"))
+	}
+	return errors.Join(errs...)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
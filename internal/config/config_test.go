@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDSN(t *testing.T) {
+	passwords := []string{"secret", "with space", "it's", `back\slash`, `\'; host=evil`, ""}
+	for _, password := range passwords {
+		db := DBConfig{Host: "db.local", Port: 6543, User: "admin", Password: password, Name: "admin api", SSLMode: "disable"}
+		parsed, err := pgconn.ParseConfig(db.DSN())
+		if err != nil {
+			t.Errorf("password %q: %v", password, err)
+			continue
+		}
+		if parsed.Password != password || parsed.Host != "db.local" || parsed.Port != 6543 ||
+			parsed.User != "admin" || parsed.Database != "admin api" {
+			t.Errorf("password %q: DSN %q parsed as %s@%s:%d/%s password %q",
+				password, db.DSN(), parsed.User, parsed.Host, parsed.Port, parsed.Database, parsed.Password)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ByteSize
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"0", 0, false},
+		{"10B", 10, false},
+		{"512KB", 512 << 10, false},
+		{"20MB", 20 << 20, false},
+		{" 2 gb ", 2 << 30, false},
+		{"8589934591GB", 8589934591 << 30, false},
+		{"8589934592GB", 0, true},
+		{"99999999999G", 0, true},
+		{"99999999999GB", 0, true},
+		{"9223372036854775807", 9223372036854775807, false},
+		{"9223372036854775808", 0, true},
+		{"-1MB", 0, true},
+		{"1.5MB", 0, true},
+		{"MB", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "p'w")
+	t.Setenv("DB_PORT", "6000")
+	t.Setenv("UPLOAD_LIMIT_DOCS", "5MB")
+	t.Setenv("CORS_ORIGINS", "https://a.example, https://b.example")
+	t.Setenv("SESSION_TTL", "2h")
+
+	cfg, err := Load("", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Password != "p'w" || cfg.DB.Port != 6000 || cfg.Uploads.Limits.Docs != 5<<20 ||
+		len(cfg.CORS.Origins) != 2 || cfg.CORS.Origins[1] != "https://b.example" || cfg.Auth.SessionTTL != Duration(2*time.Hour) {
+		t.Errorf("env not applied: %+v", cfg)
+	}
+	// Settings without a variable keep their defaults.
+	def := defaults()
+	if cfg.DB.Host != def.DB.Host || cfg.Uploads.Limits.Gallery != def.Uploads.Limits.Gallery || cfg.HTTP.Addr != def.HTTP.Addr {
+		t.Errorf("defaults lost: %+v", cfg)
+	}
+}
+
+func TestLoadEnvErrors(t *testing.T) {
+	tests := []struct{ name, value string }{
+		{"DB_PORT", "abc"},
+		{"UPLOAD_MAX_SIZE", "99999999999GB"},
+		{"SESSION_TTL", "day"},
+		{"UPLOAD_BACKEND", "ftp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DB_PASSWORD", "secret")
+			t.Setenv(tt.name, tt.value)
+			if _, err := Load("", false); err == nil {
+				t.Errorf("%s=%s accepted", tt.name, tt.value)
+			}
+		})
+	}
+}
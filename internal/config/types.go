@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration written as "24h" or "30m" in YAML.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// ByteSize is a size in bytes written as "10MB", "512KB" or a plain number.
+type ByteSize int64
+
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// ParseByteSize understands B, KB, MB and GB suffixes (powers of 1024).
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multipliers := []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	factor := int64(1)
+	for _, m := range multipliers {
+		if strings.HasSuffix(s, m.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, m.suffix))
+			factor = m.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("неверный размер %q", s)
+	}
+	if n > math.MaxInt64/factor {
+		return 0, fmt.Errorf("размер %q слишком большой", s)
+	}
+	return ByteSize(n * factor), nil
+}
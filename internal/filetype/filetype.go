@@ -0,0 +1,148 @@
+package filetype
+
+// Package filetype identifies uploaded files by their content rather than by
+// the name the client sent. http.DetectContentType covers images and PDF;
+// Office formats need a look inside the container.
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+	PDF  = "application/pdf"
+	DOC  = "application/msword"
+	XLS  = "application/vnd.ms-excel"
+	DOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	XLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Images and Documents are the allow-lists for gallery and docs uploads.
+var (
+	Images    = []string{JPEG, PNG, GIF, WebP}
+	Documents = []string{PDF, DOC, XLS, DOCX, XLSX}
+)
+
+var extensions = map[string]string{
+	JPEG: ".jpg",
+	PNG:  ".png",
+	GIF:  ".gif",
+	WebP: ".webp",
+	PDF:  ".pdf",
+	DOC:  ".doc",
+	XLS:  ".xls",
+	DOCX: ".docx",
+	XLSX: ".xlsx",
+}
+
+// Ext returns the canonical file extension for a detected MIME type.
+func Ext(mimeType string) string {
+	return extensions[mimeType]
+}
+
+// File is what an upload must provide: random access for zip inspection and
+// sequential reads for the rest.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// Detect returns the MIME type of f judged by its content. The read position
+// of f is restored before returning.
+func Detect(f File, size int64) (string, error) {
+	head := make([]byte, 512)
+	n, err := f.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+
+	detected := http.DetectContentType(head)
+	if i := strings.IndexByte(detected, ';'); i >= 0 {
+		detected = detected[:i]
+	}
+
+	switch {
+	case detected == JPEG || detected == PNG || detected == GIF || detected == WebP:
+		// Проверяем, что за сигнатурой действительно лежит картинка.
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		_, format, err := image.DecodeConfig(f)
+		if _, seekErr := f.Seek(0, io.SeekStart); seekErr != nil {
+			return "", seekErr
+		}
+		if err != nil || "image/"+format != detected {
+			return "application/octet-stream", nil
+		}
+		return detected, nil
+
+	case detected == PDF:
+		return PDF, nil
+
+	case detected == "application/zip":
+		return detectOOXML(f, size)
+
+	case bytes.HasPrefix(head, oleMagic):
+		return detectOLE(f, size), nil
+	}
+	return detected, nil
+}
+
+// detectOOXML distinguishes DOCX and XLSX by their main part.
+func detectOOXML(f io.ReaderAt, size int64) (string, error) {
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return "application/octet-stream", nil
+	}
+	for _, entry := range zr.File {
+		switch entry.Name {
+		case "word/document.xml":
+			return DOCX, nil
+		case "xl/workbook.xml":
+			return XLSX, nil
+		}
+	}
+	return "application/zip", nil
+}
+
+// detectOLE tells Word and Excel 97–2003 files from other OLE containers
+// by the streams they hold.
+func detectOLE(f io.ReaderAt, size int64) string {
+	streams, err := oleStreams(f, size)
+	switch {
+	case err != nil:
+		return "application/octet-stream"
+	case streams["WordDocument"]:
+		return DOC
+	case streams["Workbook"] || streams["Book"]:
+		return XLS
+	}
+	return "application/x-ole-storage"
+}
+
+// Allowed reports whether mimeType is in the list.
+func Allowed(mimeType string, list []string) bool {
+	for _, m := range list {
+		if m == mimeType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"unicode/utf16"
+)
+
+const (
+	oleFreeSect = 0xFFFFFFFF
+	oleFATSect  = 0xFFFFFFFD
+)
+
+// cfb builds a compound file with 512-byte sectors: the FAT in sector 0 and
+// the directory in the sectors after it, holding a root entry and one stream
+// entry per name. fat, when set, may rewrite the FAT before it is written.
+func cfb(names []string, fat func([]uint32)) []byte {
+	entries := append([]string{"Root Entry"}, names...)
+	dirSectors := (len(entries)*oleDirEntry + 511) / 512
+
+	header := make([]byte, oleHeaderSize)
+	copy(header, oleMagic)
+	binary.LittleEndian.PutUint16(header[0x18:], 0x3E)
+	binary.LittleEndian.PutUint16(header[0x1A:], 3)
+	binary.LittleEndian.PutUint16(header[0x1C:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[0x1E:], 9)
+	binary.LittleEndian.PutUint16(header[0x20:], 6)
+	binary.LittleEndian.PutUint32(header[0x2C:], 1)
+	binary.LittleEndian.PutUint32(header[0x30:], 1)
+	binary.LittleEndian.PutUint32(header[0x38:], 0x1000)
+	binary.LittleEndian.PutUint32(header[0x3C:], oleEndOfChain)
+	binary.LittleEndian.PutUint32(header[0x44:], oleEndOfChain)
+	for i := 0; i < oleHeaderDIFAT; i++ {
+		binary.LittleEndian.PutUint32(header[0x4C+4*i:], oleFreeSect)
+	}
+	binary.LittleEndian.PutUint32(header[0x4C:], 0)
+
+	table := make([]uint32, 128)
+	for i := range table {
+		table[i] = oleFreeSect
+	}
+	table[0] = oleFATSect
+	for i := 1; i <= dirSectors; i++ {
+		table[i] = uint32(i + 1)
+	}
+	table[dirSectors] = oleEndOfChain
+	if fat != nil {
+		fat(table)
+	}
+	fatSector := make([]byte, 512)
+	for i, v := range table {
+		binary.LittleEndian.PutUint32(fatSector[4*i:], v)
+	}
+
+	dir := make([]byte, dirSectors*512)
+	for i, name := range entries {
+		entry := dir[i*oleDirEntry : (i+1)*oleDirEntry]
+		units := utf16.Encode([]rune(name))
+		for j, u := range units {
+			binary.LittleEndian.PutUint16(entry[2*j:], u)
+		}
+		binary.LittleEndian.PutUint16(entry[0x40:], uint16(2*len(units)+2))
+		entry[0x42] = oleTypeStream
+		if i == 0 {
+			entry[0x42] = 5
+		}
+	}
+
+	return append(append(header, fatSector...), dir...)
+}
+
+func zipOf(names ...string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, _ := zw.Create(name)
+		w.Write([]byte("<xml/>"))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func pngOf() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	doc := cfb([]string{"\x01CompObj", "WordDocument", "1Table", "\x05SummaryInformation"}, nil)
+	xls := cfb([]string{"Workbook", "\x05DocumentSummaryInformation"}, nil)
+	xls95 := cfb([]string{"Book"}, nil)
+	ppt := cfb([]string{"PowerPoint Document", "Current User"}, nil)
+	msi := cfb([]string{"䡀㼿䕷汬", "\x05SummaryInformation"}, nil)
+	// A directory spanning three sectors with WordDocument in the last one.
+	longDir := cfb([]string{"s1", "s2", "s3", "s4", "s5", "s6", "s7", "s8", "s9", "WordDocument"}, nil)
+	cyclic := cfb([]string{"WordDocument"}, func(fat []uint32) { fat[1] = 1 })
+	outside := cfb([]string{"WordDocument"}, func(fat []uint32) { fat[1] = 1000 })
+	truncatedDir := doc[:oleHeaderSize+512+100]
+	pngData := pngOf()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"doc", doc, DOC},
+		{"xls", xls, XLS},
+		{"xls 95", xls95, XLS},
+		{"ppt", ppt, "application/x-ole-storage"},
+		{"msi", msi, "application/x-ole-storage"},
+		{"long directory", longDir, DOC},
+		{"cyclic fat", cyclic, "application/octet-stream"},
+		{"chain outside file", outside, "application/octet-stream"},
+		{"truncated header", doc[:100], "application/octet-stream"},
+		{"truncated directory", truncatedDir, "application/octet-stream"},
+		{"magic only", oleMagic, "application/octet-stream"},
+		{"docx", zipOf("[Content_Types].xml", "word/document.xml"), DOCX},
+		{"xlsx", zipOf("[Content_Types].xml", "xl/workbook.xml"), XLSX},
+		{"plain zip", zipOf("readme.txt"), "application/zip"},
+		{"broken zip", []byte("PK\x03\x04garbage"), "application/octet-stream"},
+		{"png", pngData, PNG},
+		{"png signature only", pngData[:16], "application/octet-stream"},
+		{"pdf", []byte("%PDF-1.4\n1 0 obj\n"), PDF},
+		{"text", []byte("just some text"), "text/plain"},
+		{"empty", nil, "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.data)
+			got, err := Detect(r, int64(len(tt.data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Detect = %q, want %q", got, tt.want)
+			}
+			if pos, _ := r.Seek(0, 1); pos != 0 {
+				t.Errorf("read position = %d, want 0", pos)
+			}
+		})
+	}
+}
+
+// countingReader counts ReadAt calls to check that detection does a bounded
+// amount of work whatever the input.
+type countingReader struct {
+	*bytes.Reader
+	reads int
+}
+
+func (r *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	r.reads++
+	return r.Reader.ReadAt(p, off)
+}
+
+func FuzzDetect(f *testing.F) {
+	f.Add(cfb([]string{"WordDocument"}, nil))
+	f.Add(cfb([]string{"Workbook"}, func(fat []uint32) { fat[1] = 1 }))
+	f.Add(zipOf("word/document.xml"))
+	f.Add(pngOf())
+	f.Add([]byte("%PDF-1.7"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := &countingReader{Reader: bytes.NewReader(data)}
+		if _, err := Detect(r, int64(len(data))); err != nil {
+			t.Fatal(err)
+		}
+		if max := oleMaxReads + 64 + len(data)/64; r.reads > max {
+			t.Errorf("%d reads for %d bytes, want at most %d", r.reads, len(data), max)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package filetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"unicode/utf16"
+)
+
+// OLE compound files (MS-CFB) are a small FAT file system. Word 97–2003
+// keeps the document in a "WordDocument" stream and Excel in "Workbook"
+// ("Book" before Excel 97); MSI installers and other containers share the
+// same header, so the directory has to be read to tell them apart.
+
+var oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+const (
+	oleHeaderSize = 512
+	oleEndOfChain = 0xFFFFFFFE
+	oleMaxSector  = 0xFFFFFFFA
+	// oleHeaderDIFAT is the number of FAT sector locations kept in the header.
+	oleHeaderDIFAT = 109
+	oleDirEntry    = 128
+	oleTypeStream  = 2
+	// oleMaxReads caps the sectors read while looking for the streams. Real
+	// documents need a handful; a crafted file could otherwise make every
+	// FAT lookup walk a long DIFAT chain.
+	oleMaxReads = 4096
+)
+
+var errBadOLE = errors.New("filetype: malformed OLE compound file")
+
+type compoundFile struct {
+	r      io.ReaderAt
+	size   int64
+	shift  uint
+	header []byte
+	reads  int
+}
+
+// oleStreams returns the names of all streams in the directory of an OLE
+// compound file.
+func oleStreams(r io.ReaderAt, size int64) (map[string]bool, error) {
+	header := make([]byte, oleHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, errBadOLE
+	}
+	if string(header[:8]) != string(oleMagic) || binary.LittleEndian.Uint16(header[0x1C:]) != 0xFFFE {
+		return nil, errBadOLE
+	}
+	shift := uint(binary.LittleEndian.Uint16(header[0x1E:]))
+	if shift != 9 && shift != 12 {
+		return nil, errBadOLE
+	}
+	cf := &compoundFile{r: r, size: size, shift: shift, header: header}
+
+	// A chain can't be longer than the number of sectors in the file; a
+	// longer one is a loop.
+	maxSteps := size >> shift
+	streams := map[string]bool{}
+	sector := binary.LittleEndian.Uint32(header[0x30:])
+	for steps := int64(0); sector != oleEndOfChain; steps++ {
+		if steps > maxSteps {
+			return nil, errBadOLE
+		}
+		data, err := cf.sector(sector)
+		if err != nil {
+			return nil, err
+		}
+		for off := 0; off+oleDirEntry <= len(data); off += oleDirEntry {
+			entry := data[off : off+oleDirEntry]
+			nameLen := int(binary.LittleEndian.Uint16(entry[0x40:]))
+			if entry[0x42] != oleTypeStream || nameLen < 2 || nameLen > 64 || nameLen%2 != 0 {
+				continue
+			}
+			streams[utf16Name(entry[:nameLen-2])] = true
+		}
+		if sector, err = cf.next(sector); err != nil {
+			return nil, err
+		}
+	}
+	return streams, nil
+}
+
+// sector reads sector n; sector 0 starts right after the header.
+func (cf *compoundFile) sector(n uint32) ([]byte, error) {
+	if n > oleMaxSector || cf.reads >= oleMaxReads {
+		return nil, errBadOLE
+	}
+	cf.reads++
+	data := make([]byte, 1<<cf.shift)
+	off := (int64(n) + 1) << cf.shift
+	if off+int64(len(data)) > cf.size {
+		return nil, errBadOLE
+	}
+	if _, err := cf.r.ReadAt(data, off); err != nil {
+		return nil, errBadOLE
+	}
+	return data, nil
+}
+
+// next follows the FAT from sector n.
+func (cf *compoundFile) next(n uint32) (uint32, error) {
+	perSector := uint32(1<<cf.shift) / 4
+	fat, err := cf.fatSector(n / perSector)
+	if err != nil {
+		return 0, err
+	}
+	data, err := cf.sector(fat)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(data[4*(n%perSector):]), nil
+}
+
+// fatSector returns the location of the i-th FAT sector: the first 109 are
+// listed in the header, the rest in a chain of DIFAT sectors.
+func (cf *compoundFile) fatSector(i uint32) (uint32, error) {
+	if i < oleHeaderDIFAT {
+		return binary.LittleEndian.Uint32(cf.header[0x4C+4*i:]), nil
+	}
+	i -= oleHeaderDIFAT
+	perSector := uint32(1<<cf.shift)/4 - 1
+	sector := binary.LittleEndian.Uint32(cf.header[0x44:])
+	for steps := int64(0); steps <= cf.size>>cf.shift; steps++ {
+		data, err := cf.sector(sector)
+		if err != nil {
+			return 0, err
+		}
+		if i < perSector {
+			return binary.LittleEndian.Uint32(data[4*i:]), nil
+		}
+		i -= perSector
+		sector = binary.LittleEndian.Uint32(data[4*perSector:])
+	}
+	return 0, errBadOLE
+}
+
+func utf16Name(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,157 @@
+package gc
+
+// Package gc reconciles the upload storage with the database: files that no
+// row references any more are orphans, rows pointing to absent files are
+// reported as missing.
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"admin-api/internal/storage"
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+// references lists every column that stores a URL of an uploaded file.
+var references = []struct {
+	model  interface{}
+	column string
+}{
+	{&models.Gallery{}, "filename"},
+	{&models.GalleryVariant{}, "filename"},
+	{&models.Docs{}, "file"},
+	{&models.Users{}, "avatar"},
+}
+
+// mentions lists free-text columns where links to uploads are typed by hand,
+// e.g. the legacy services.src or an image in a Markdown description. Files
+// mentioned there are never collected, but they hold no models.Files
+// reference, so Verify doesn't count them.
+var mentions = []struct {
+	model  interface{}
+	column string
+}{
+	{&models.Services{}, "src"},
+	{&models.Services{}, "text"},
+}
+
+type Options struct {
+	// Remove deletes orphans instead of only reporting them.
+	Remove bool
+	// MinAge protects files that were just written and whose DB row may not
+	// be committed yet.
+	MinAge time.Duration
+}
+
+type Report struct {
+	Stored     int
+	Referenced int
+	Orphans    []storage.Object
+	Missing    []string
+	Removed    int
+}
+
+func Run(ctx context.Context, db *gorm.DB, store storage.Storage, opts Options) (Report, error) {
+	var report Report
+
+	used, err := usage(ctx, db, store)
+	if err != nil {
+		return report, err
+	}
+	referenced := map[string]bool{}
+	for key := range used {
+		referenced[key] = true
+	}
+	// Deduplicated blobs are kept while their files row exists; counters
+	// that drifted from reality are Verify's job.
+	var keys []string
+	if err := db.WithContext(ctx).Model(&models.Files{}).Pluck("key", &keys).Error; err != nil {
+		return report, err
+	}
+	for _, key := range keys {
+		referenced[key] = true
+	}
+	linked, err := mentioned(ctx, db, store)
+	if err != nil {
+		return report, err
+	}
+	for key := range linked {
+		referenced[key] = true
+	}
+	report.Referenced = len(referenced)
+
+	objects, err := store.List(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.Stored = len(objects)
+
+	stored := map[string]bool{}
+	cutoff := time.Now().Add(-opts.MinAge)
+	for _, obj := range objects {
+		stored[obj.Key] = true
+		if referenced[obj.Key] || obj.ModTime.After(cutoff) {
+			continue
+		}
+		report.Orphans = append(report.Orphans, obj)
+		if opts.Remove {
+			if err := store.Delete(ctx, obj.Key); err != nil {
+				return report, err
+			}
+			report.Removed++
+		}
+	}
+
+	for key := range referenced {
+		if !stored[key] {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+	sort.Strings(report.Missing)
+	return report, nil
+}
+
+// mentioned finds storage keys linked from the mentions columns.
+func mentioned(ctx context.Context, db *gorm.DB, store storage.Storage) (map[string]bool, error) {
+	base := store.URL("")
+	keys := map[string]bool{}
+	for _, ref := range mentions {
+		var texts []string
+		if err := db.WithContext(ctx).Model(ref.model).Where(ref.column+" <> ''").Pluck(ref.column, &texts).Error; err != nil {
+			return nil, err
+		}
+		for _, text := range texts {
+			for _, key := range linkedKeys(base, text) {
+				keys[key] = true
+			}
+		}
+	}
+	return keys, nil
+}
+
+var keyChars = regexp.MustCompile(`^[A-Za-z0-9._-]+`)
+
+// linkedKeys returns the keys of all links to base found in text. A link may
+// be absolute ("http://host/uploads/x.jpg") or relative ("/uploads/x.jpg"),
+// so base is searched anywhere; a dot ending a sentence is not part of a key.
+func linkedKeys(base, text string) []string {
+	var keys []string
+	if base == "" {
+		return nil
+	}
+	for {
+		i := strings.Index(text, base)
+		if i < 0 {
+			return keys
+		}
+		text = text[i+len(base):]
+		if key := strings.TrimRight(keyChars.FindString(text), "."); key != "" {
+			keys = append(keys, key)
+		}
+	}
+}
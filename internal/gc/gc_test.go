@@ -0,0 +1,94 @@
+package gc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"admin-api/internal/dbtest"
+	"admin-api/internal/storage"
+	"admin-api/models"
+)
+
+func TestLinkedKeys(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"/uploads/a.jpg", []string{"a.jpg"}},
+		{"http://localhost:8080/uploads/b.png", []string{"b.png"}},
+		{"См. фото /uploads/c.jpg.", []string{"c.jpg"}},
+		{`![бассейн](/uploads/d_1.webp "Бассейн") и <img src="/uploads/e-2.jpg">`, []string{"d_1.webp", "e-2.jpg"}},
+		{"/uploads/f.jpg?v=2#top", []string{"f.jpg"}},
+		{"/uploads/ и /uploads/../etc", nil},
+		{"https://example.com/img/g.jpg", nil},
+	}
+	for _, tt := range tests {
+		got := linkedKeys("/uploads/", tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("linkedKeys(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRunKeepsFilesLinkedFromServices(t *testing.T) {
+	db := dbtest.Open(t, &models.Services{}, &models.ServicePrices{}, &models.Gallery{}, &models.GalleryVariant{},
+		&models.ServiceImages{}, &models.Docs{}, &models.Users{}, &models.Files{})
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, "/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, key := range []string{"gallery.jpg", "cover.jpg", "inline.png", "orphan.jpg"} {
+		path := filepath.Join(dir, key)
+		if err := os.WriteFile(path, []byte(key), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.Create(&models.Gallery{Filename: "/uploads/gallery.jpg"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	service := models.Services{
+		Eng:   "pool",
+		Title: "Бассейн",
+		Src:   "http://localhost:8080/uploads/cover.jpg",
+		Text:  "Вид на бассейн:\n\n![бассейн](/uploads/inline.png)",
+	}
+	if err := db.Create(&service).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(context.Background(), db, store, Options{Remove: true, MinAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed != 1 || len(report.Orphans) != 1 || report.Orphans[0].Key != "orphan.jpg" {
+		t.Errorf("orphans = %+v, removed %d; want only orphan.jpg", report.Orphans, report.Removed)
+	}
+	for _, key := range []string{"gallery.jpg", "cover.jpg", "inline.png"} {
+		if _, err := os.Stat(filepath.Join(dir, key)); err != nil {
+			t.Errorf("%s: %v", key, err)
+		}
+	}
+	if len(report.Missing) != 0 {
+		t.Errorf("missing = %v", report.Missing)
+	}
+
+	verify, err := Verify(context.Background(), db, store, VerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(verify.RefCounts) != 0 {
+		t.Errorf("links from services must not count as references: %+v", verify.RefCounts)
+	}
+}
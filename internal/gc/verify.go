@@ -0,0 +1,121 @@
+package gc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"admin-api/internal/storage"
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+type VerifyOptions struct {
+	// Fix rewrites ref_count to the number of rows that actually use a file.
+	Fix bool
+}
+
+// Mismatch is a blob whose content no longer matches its recorded hash.
+type Mismatch struct {
+	Key      string
+	Expected string
+	Actual   string
+}
+
+// RefCount is a files row whose counter disagrees with the rows using it.
+type RefCount struct {
+	Key    string
+	Stored int
+	Actual int
+}
+
+type VerifyReport struct {
+	Checked   int
+	Missing   []string
+	Corrupt   []Mismatch
+	RefCounts []RefCount
+	Fixed     int
+}
+
+// Verify re-hashes every blob listed in models.Files and compares the stored
+// reference counters with the rows that point to each file.
+func Verify(ctx context.Context, db *gorm.DB, store storage.Storage, opts VerifyOptions) (VerifyReport, error) {
+	var report VerifyReport
+
+	var files []models.Files
+	if err := db.WithContext(ctx).Order("key").Find(&files).Error; err != nil {
+		return report, err
+	}
+
+	used, err := usage(ctx, db, store)
+	if err != nil {
+		return report, err
+	}
+
+	for _, file := range files {
+		report.Checked++
+
+		hash, size, err := hashObject(ctx, store, file.Key)
+		if errors.Is(err, storage.ErrNotFound) {
+			report.Missing = append(report.Missing, file.Key)
+		} else if err != nil {
+			return report, err
+		} else if hash != file.Hash || size != file.Size {
+			report.Corrupt = append(report.Corrupt, Mismatch{Key: file.Key, Expected: file.Hash, Actual: hash})
+		}
+
+		actual := used[file.Key]
+		if actual == file.RefCount {
+			continue
+		}
+		report.RefCounts = append(report.RefCounts, RefCount{Key: file.Key, Stored: file.RefCount, Actual: actual})
+		if opts.Fix {
+			// A row nobody uses is dropped; Run then sees the blob as an orphan.
+			if actual == 0 {
+				err = db.WithContext(ctx).Delete(&file).Error
+			} else {
+				err = db.WithContext(ctx).Model(&file).Update("ref_count", actual).Error
+			}
+			if err != nil {
+				return report, err
+			}
+			report.Fixed++
+		}
+	}
+	return report, nil
+}
+
+// usage counts how many rows reference each storage key.
+func usage(ctx context.Context, db *gorm.DB, store storage.Storage) (map[string]int, error) {
+	used := map[string]int{}
+	for _, ref := range references {
+		var urls []string
+		if err := db.WithContext(ctx).Model(ref.model).Where(ref.column+" <> ''").Pluck(ref.column, &urls).Error; err != nil {
+			return nil, err
+		}
+		for _, url := range urls {
+			if key, ok := storage.KeyOf(store, url); ok {
+				used[key]++
+			}
+		}
+	}
+	return used, nil
+}
+
+func hashObject(ctx context.Context, store storage.Storage, key string) (string, int64, error) {
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
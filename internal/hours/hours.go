@@ -0,0 +1,245 @@
+package hours
+
+// Package hours validates opening hours and answers whether a place is open
+// at a given moment.
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	// Embedded zone data, so LoadLocation works in minimal containers.
+	_ "time/tzdata"
+
+	"admin-api/models"
+)
+
+// horizon bounds the search for the next change: a place closed for longer
+// than this reports no next change.
+const horizon = 366
+
+// Status is whether a place is open at Now and when that changes. Next is nil
+// when the schedule has no change within the horizon.
+type Status struct {
+	Open     bool       `json:"open"`
+	Now      time.Time  `json:"now"`
+	Next     *time.Time `json:"next_change"`
+	Timezone string     `json:"timezone"`
+}
+
+// Validate checks the timezone, weekday keys, times and overlaps, including
+// intervals that run past midnight into the next day. The error text is shown
+// to the admin as is.
+func Validate(h *models.OpeningHours) error {
+	if h.Timezone == "" {
+		return fmt.Errorf("не задан часовой пояс timezone")
+	}
+	if _, err := time.LoadLocation(h.Timezone); err != nil {
+		return fmt.Errorf("неизвестный часовой пояс %q", h.Timezone)
+	}
+	for day, intervals := range h.Weekly {
+		if weekday(day) < 0 {
+			return fmt.Errorf("неизвестный день недели %q, доступны: mon, tue, wed, thu, fri, sat, sun", day)
+		}
+		if err := validateDay(intervals); err != nil {
+			return fmt.Errorf("%s: %w", day, err)
+		}
+	}
+	seen := map[string]bool{}
+	for _, ex := range h.Exceptions {
+		if ex.Date.IsZero() {
+			return fmt.Errorf("у исключения не задана дата")
+		}
+		if seen[ex.Date.String()] {
+			return fmt.Errorf("исключение на %s указано дважды", ex.Date)
+		}
+		seen[ex.Date.String()] = true
+		if ex.Closed && len(ex.Intervals) > 0 {
+			return fmt.Errorf("%s: выходной день не может содержать интервалы", ex.Date)
+		}
+		if err := validateDay(ex.Intervals); err != nil {
+			return fmt.Errorf("%s: %w", ex.Date, err)
+		}
+	}
+
+	// An interval past midnight must end before the next day opens.
+	for i, day := range models.WeekdayKeys {
+		next := models.WeekdayKeys[(i+1)%7]
+		if err := validateNight(h.Weekly[day], h.Weekly[next]); err != nil {
+			return fmt.Errorf("%s: %w", day, err)
+		}
+	}
+	exceptions := make(map[string]models.HoursException, len(h.Exceptions))
+	for _, ex := range h.Exceptions {
+		exceptions[ex.Date.String()] = ex
+	}
+	day := func(d time.Time) []models.Interval {
+		if ex, ok := exceptions[d.Format(models.DateLayout)]; ok {
+			return ex.Intervals
+		}
+		return h.Weekly[models.WeekdayKeys[d.Weekday()]]
+	}
+	for _, ex := range h.Exceptions {
+		prev, next := ex.Date.AddDate(0, 0, -1), ex.Date.AddDate(0, 0, 1)
+		if err := validateNight(day(prev), ex.Intervals); err != nil {
+			return fmt.Errorf("%s: %w", prev.Format(models.DateLayout), err)
+		}
+		if err := validateNight(ex.Intervals, day(next)); err != nil {
+			return fmt.Errorf("%s: %w", ex.Date, err)
+		}
+	}
+	return nil
+}
+
+// validateNight checks that the part of day's intervals after midnight does
+// not overlap the intervals of the following day. Both days must have passed
+// validateDay.
+func validateNight(day, next []models.Interval) error {
+	for _, in := range day {
+		s, err := toSpan(in)
+		if err != nil || s.end <= 24*60 {
+			continue
+		}
+		for _, other := range next {
+			o, err := toSpan(other)
+			if err == nil && o.start < s.end-24*60 {
+				return fmt.Errorf("интервал %s–%s пересекается с интервалом %s–%s следующего дня", in.Open, in.Close, other.Open, other.Close)
+			}
+		}
+	}
+	return nil
+}
+
+// span is an interval in minutes from the start of its day; end may exceed
+// 24h for intervals that run past midnight.
+type span struct{ start, end int }
+
+func validateDay(intervals []models.Interval) error {
+	spans := make([]span, 0, len(intervals))
+	for _, in := range intervals {
+		s, err := toSpan(in)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, s)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start < spans[i-1].end {
+			return fmt.Errorf("интервалы пересекаются")
+		}
+	}
+	return nil
+}
+
+func toSpan(in models.Interval) (span, error) {
+	open, err := minutes(in.Open)
+	if err != nil || open == 24*60 {
+		return span{}, fmt.Errorf("неверное время открытия %q, нужен формат ЧЧ:ММ", in.Open)
+	}
+	closing, err := minutes(in.Close)
+	if err != nil {
+		return span{}, fmt.Errorf("неверное время закрытия %q, нужен формат ЧЧ:ММ", in.Close)
+	}
+	if closing == open {
+		return span{}, fmt.Errorf("интервал %s–%s пустой", in.Open, in.Close)
+	}
+	if closing < open {
+		closing += 24 * 60
+	}
+	return span{open, closing}, nil
+}
+
+// minutes parses "HH:MM" into minutes since midnight; "24:00" is allowed.
+func minutes(s string) (int, error) {
+	var h, m int
+	if len(s) != 5 || s[2] != ':' {
+		return 0, fmt.Errorf("bad time")
+	}
+	if _, err := fmt.Sscanf(s, "%02d:%02d", &h, &m); err != nil {
+		return 0, err
+	}
+	if h > 24 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("bad time")
+	}
+	return h*60 + m, nil
+}
+
+func weekday(key string) time.Weekday {
+	for i, k := range models.WeekdayKeys {
+		if k == key {
+			return time.Weekday(i)
+		}
+	}
+	return -1
+}
+
+// At reports the status at now. h must have passed Validate.
+func At(h *models.OpeningHours, now time.Time) Status {
+	loc, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	status := Status{Now: now, Timezone: h.Timezone}
+
+	exceptions := make(map[string]models.HoursException, len(h.Exceptions))
+	for _, ex := range h.Exceptions {
+		exceptions[ex.Date.String()] = ex
+	}
+
+	// Start a day early to catch an interval that began yesterday and runs
+	// past midnight.
+	type period struct{ from, to time.Time }
+	var periods []period
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	for i := -1; i <= horizon; i++ {
+		day := today.AddDate(0, 0, i)
+		intervals := h.Weekly[models.WeekdayKeys[day.Weekday()]]
+		if ex, ok := exceptions[day.Format(models.DateLayout)]; ok {
+			intervals = ex.Intervals
+			if ex.Closed {
+				intervals = nil
+			}
+		}
+		spans := make([]span, 0, len(intervals))
+		for _, in := range intervals {
+			if s, err := toSpan(in); err == nil {
+				spans = append(spans, s)
+			}
+		}
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		for _, s := range spans {
+			from := time.Date(day.Year(), day.Month(), day.Day(), s.start/60, s.start%60, 0, 0, loc)
+			to := time.Date(day.Year(), day.Month(), day.Day(), s.end/60, s.end%60, 0, 0, loc)
+			// Merge with the previous period when they touch, e.g. 20:00–24:00
+			// followed by 00:00–02:00.
+			if n := len(periods); n > 0 && !from.After(periods[n-1].to) {
+				if to.After(periods[n-1].to) {
+					periods[n-1].to = to
+				}
+				continue
+			}
+			periods = append(periods, period{from, to})
+		}
+	}
+
+	// A period that reaches the end of the horizon, like every day of a 24/7
+	// schedule merged into one, has no known end.
+	end := today.AddDate(0, 0, horizon+1)
+	for _, p := range periods {
+		if now.Before(p.from) {
+			next := p.from
+			status.Next = &next
+			return status
+		}
+		if now.Before(p.to) {
+			status.Open = true
+			if p.to.Before(end) {
+				next := p.to
+				status.Next = &next
+			}
+			return status
+		}
+	}
+	return status
+}
@@ -0,0 +1,295 @@
+package hours
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"admin-api/models"
+)
+
+func everyDay(intervals ...models.Interval) map[string][]models.Interval {
+	weekly := map[string][]models.Interval{}
+	for _, key := range models.WeekdayKeys {
+		weekly[key] = intervals
+	}
+	return weekly
+}
+
+func closedOn(date string) models.HoursException {
+	d, _ := models.ParseDate(date)
+	return models.HoursException{Date: d, Closed: true}
+}
+
+func openOn(date string, intervals ...models.Interval) models.HoursException {
+	d, _ := models.ParseDate(date)
+	return models.HoursException{Date: d, Intervals: intervals}
+}
+
+func TestAt(t *testing.T) {
+	daytime := models.Interval{Open: "09:00", Close: "18:00"}
+	night := models.Interval{Open: "22:00", Close: "06:00"}
+	allDay := models.Interval{Open: "00:00", Close: "24:00"}
+
+	// 2026-10-16 is a Friday.
+	tests := []struct {
+		name     string
+		hours    models.OpeningHours
+		now      string
+		wantOpen bool
+		wantNext string
+	}{
+		{
+			name:     "before opening",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(daytime)},
+			now:      "2026-10-17T08:00:00+03:00",
+			wantNext: "2026-10-17T09:00:00+03:00",
+		},
+		{
+			name:     "open",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(daytime)},
+			now:      "2026-10-17T12:00:00+03:00",
+			wantOpen: true,
+			wantNext: "2026-10-17T18:00:00+03:00",
+		},
+		{
+			name:     "closing time is closed",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(daytime)},
+			now:      "2026-10-17T18:00:00+03:00",
+			wantNext: "2026-10-18T09:00:00+03:00",
+		},
+		{
+			name:     "now in another zone",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(daytime)},
+			now:      "2026-10-17T05:30:00Z",
+			wantNext: "2026-10-17T09:00:00+03:00",
+		},
+		{
+			name:     "overnight, before it starts",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: map[string][]models.Interval{"fri": {night}}},
+			now:      "2026-10-16T21:00:00+03:00",
+			wantNext: "2026-10-16T22:00:00+03:00",
+		},
+		{
+			name:     "overnight, after midnight",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: map[string][]models.Interval{"fri": {night}}},
+			now:      "2026-10-17T01:00:00+03:00",
+			wantOpen: true,
+			wantNext: "2026-10-17T06:00:00+03:00",
+		},
+		{
+			name: "touching intervals merge across midnight",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: map[string][]models.Interval{
+				"fri": {{Open: "20:00", Close: "24:00"}},
+				"sat": {{Open: "00:00", Close: "02:00"}},
+			}},
+			now:      "2026-10-16T23:00:00+03:00",
+			wantOpen: true,
+			wantNext: "2026-10-17T02:00:00+03:00",
+		},
+		{
+			name: "closed by exception",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(daytime), Exceptions: []models.HoursException{
+				closedOn("2026-10-19"),
+				openOn("2026-10-20", models.Interval{Open: "10:00", Close: "15:00"}),
+			}},
+			now:      "2026-10-18T19:00:00+03:00",
+			wantNext: "2026-10-20T10:00:00+03:00",
+		},
+		{
+			name: "shortened day",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(daytime), Exceptions: []models.HoursException{
+				openOn("2026-10-20", models.Interval{Open: "10:00", Close: "15:00"}),
+			}},
+			now:      "2026-10-20T12:00:00+03:00",
+			wantOpen: true,
+			wantNext: "2026-10-20T15:00:00+03:00",
+		},
+		{
+			name: "open by exception on a day off",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: map[string][]models.Interval{"mon": {daytime}}, Exceptions: []models.HoursException{
+				openOn("2026-10-17", models.Interval{Open: "11:00", Close: "13:00"}),
+			}},
+			now:      "2026-10-17T10:00:00+03:00",
+			wantNext: "2026-10-17T11:00:00+03:00",
+		},
+		{
+			name:     "24/7",
+			hours:    models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(allDay)},
+			now:      "2026-10-17T12:00:00+03:00",
+			wantOpen: true,
+		},
+		{
+			name: "24/7 until a holiday",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: everyDay(allDay), Exceptions: []models.HoursException{
+				closedOn("2026-12-31"),
+			}},
+			now:      "2026-10-17T12:00:00+03:00",
+			wantOpen: true,
+			wantNext: "2026-12-31T00:00:00+03:00",
+		},
+		{
+			name:  "never open",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow"},
+			now:   "2026-10-17T12:00:00+03:00",
+		},
+		{
+			name:     "spring forward",
+			hours:    models.OpeningHours{Timezone: "Europe/Berlin", Weekly: everyDay(daytime)},
+			now:      "2026-03-28T20:00:00+01:00",
+			wantNext: "2026-03-29T09:00:00+02:00",
+		},
+		{
+			name:     "overnight over the spring gap",
+			hours:    models.OpeningHours{Timezone: "Europe/Berlin", Weekly: everyDay(night)},
+			now:      "2026-03-29T03:30:00+02:00",
+			wantOpen: true,
+			wantNext: "2026-03-29T06:00:00+02:00",
+		},
+		{
+			name:     "overnight over the autumn repeat",
+			hours:    models.OpeningHours{Timezone: "Europe/Berlin", Weekly: everyDay(night)},
+			now:      "2026-10-24T23:00:00+02:00",
+			wantOpen: true,
+			wantNext: "2026-10-25T06:00:00+01:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := At(&tt.hours, now)
+			if !status.Now.Equal(now) || status.Now.Location().String() != tt.hours.Timezone {
+				t.Errorf("now = %v, want %v in %s", status.Now, now, tt.hours.Timezone)
+			}
+			if status.Open != tt.wantOpen {
+				t.Errorf("open = %v, want %v", status.Open, tt.wantOpen)
+			}
+			switch {
+			case tt.wantNext == "" && status.Next != nil:
+				t.Errorf("next_change = %v, want none", status.Next)
+			case tt.wantNext != "" && status.Next == nil:
+				t.Errorf("next_change = none, want %s", tt.wantNext)
+			case tt.wantNext != "":
+				want, err := time.Parse(time.RFC3339, tt.wantNext)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !status.Next.Equal(want) {
+					t.Errorf("next_change = %v, want %v", status.Next, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   models.OpeningHours
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: map[string][]models.Interval{"mon": {{Open: "09:00", Close: "13:00"}, {Open: "14:00", Close: "24:00"}}}},
+		},
+		{
+			name:  "overnight",
+			hours: models.OpeningHours{Timezone: "Europe/Moscow", Weekly: map[string][]models.Interval{"fri": {{Open: "22:00", Close: "04:00"}}}},
+		},
+		{
+			name:    "no time zone",
+			hours:   models.OpeningHours{},
+			wantErr: "не задан часовой пояс",
+		},
+		{
+			name:    "unknown time zone",
+			hours:   models.OpeningHours{Timezone: "Europe/Atlantis"},
+			wantErr: "неизвестный часовой пояс",
+		},
+		{
+			name:    "unknown weekday",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"monday": nil}},
+			wantErr: "неизвестный день недели",
+		},
+		{
+			name:    "bad time",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"mon": {{Open: "9:00", Close: "18:00"}}}},
+			wantErr: "неверное время открытия",
+		},
+		{
+			name:    "opening at 24:00",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"mon": {{Open: "24:00", Close: "02:00"}}}},
+			wantErr: "неверное время открытия",
+		},
+		{
+			name:    "empty interval",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"mon": {{Open: "10:00", Close: "10:00"}}}},
+			wantErr: "пустой",
+		},
+		{
+			name:    "overlap",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"mon": {{Open: "09:00", Close: "13:00"}, {Open: "12:00", Close: "18:00"}}}},
+			wantErr: "пересекаются",
+		},
+		{
+			name:    "overnight into the next day",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"fri": {{Open: "22:00", Close: "04:00"}}, "sat": {{Open: "02:00", Close: "10:00"}}}},
+			wantErr: "fri: интервал 22:00–04:00 пересекается с интервалом 02:00–10:00",
+		},
+		{
+			name:    "overnight from sunday into monday",
+			hours:   models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"sun": {{Open: "20:00", Close: "09:30"}}, "mon": {{Open: "09:00", Close: "18:00"}}}},
+			wantErr: "sun: интервал 20:00–09:30",
+		},
+		{
+			name:  "overnight up to the next opening",
+			hours: models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"fri": {{Open: "22:00", Close: "04:00"}}, "sat": {{Open: "04:00", Close: "10:00"}}}},
+		},
+		{
+			name: "overnight into an exception",
+			hours: models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"thu": {{Open: "20:00", Close: "03:00"}}},
+				Exceptions: []models.HoursException{openOn("2026-01-02", models.Interval{Open: "00:00", Close: "12:00"})}},
+			wantErr: "2026-01-01: интервал 20:00–03:00",
+		},
+		{
+			name: "exception overnight into the weekly schedule",
+			hours: models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"fri": {{Open: "01:00", Close: "12:00"}}},
+				Exceptions: []models.HoursException{openOn("2026-01-01", models.Interval{Open: "18:00", Close: "02:00"})}},
+			wantErr: "2026-01-01: интервал 18:00–02:00",
+		},
+		{
+			name: "exception closes the overlapping day",
+			hours: models.OpeningHours{Timezone: "UTC", Weekly: map[string][]models.Interval{"thu": {{Open: "20:00", Close: "03:00"}}},
+				Exceptions: []models.HoursException{closedOn("2026-01-02")}},
+		},
+		{
+			name:    "duplicate exception",
+			hours:   models.OpeningHours{Timezone: "UTC", Exceptions: []models.HoursException{closedOn("2026-12-31"), closedOn("2026-12-31")}},
+			wantErr: "указано дважды",
+		},
+		{
+			name: "closed exception with intervals",
+			hours: models.OpeningHours{Timezone: "UTC", Exceptions: []models.HoursException{
+				{Date: models.NewDate(2026, 12, 31), Closed: true, Intervals: []models.Interval{{Open: "10:00", Close: "12:00"}}},
+			}},
+			wantErr: "выходной день не может содержать интервалы",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.hours)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package imaging
+
+// Package imaging builds resized copies of gallery photos so the public site
+// can pick a suitable size through srcset instead of downloading originals.
+// Copies are made in JPEG and, when built with -tags webp (cgo and a C
+// compiler are needed for libwebp), also in WebP.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+
+	// Декодеры форматов, которые принимаются на вход.
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Widths are the target widths of the generated variants in pixels.
+var Widths = []int{320, 800, 1600}
+
+const (
+	jpegQuality = 82
+	webpQuality = 80
+)
+
+// Variant formats.
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// Formats lists the variant formats this build can encode.
+var Formats = supportedFormats()
+
+func supportedFormats() []string {
+	if webpSupported {
+		return []string{FormatJPEG, FormatWebP}
+	}
+	return []string{FormatJPEG}
+}
+
+type Variant struct {
+	Width  int
+	Height int
+	Format string
+	Data   []byte
+}
+
+// MimeType returns the content type of the variant, e.g. "image/webp".
+func (v Variant) MimeType() string {
+	return "image/" + v.Format
+}
+
+// ErrTooLarge is returned when an image has more pixels than allowed.
+var ErrTooLarge = errors.New("imaging: image dimensions exceed the limit")
+
+// Decode reads an image in any registered format. The header is checked
+// first, so an upload that declares huge dimensions is rejected before the
+// decoder allocates memory for it. maxPixels <= 0 disables the check.
+func Decode(data []byte, maxPixels int64) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrCorrupt
+	}
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return img, nil
+}
+
+// Variants returns a copy of img in each of formats for every width in
+// Widths that is smaller than the image itself. Images narrower than the
+// smallest width get variants in their own size, so every photo has at least
+// one per format.
+func Variants(img image.Image, formats []string) ([]Variant, error) {
+	bounds := img.Bounds()
+	var sizes []image.Image
+	for _, width := range Widths {
+		if width < bounds.Dx() {
+			sizes = append(sizes, resize(img, width))
+		}
+	}
+	if len(sizes) == 0 {
+		sizes = append(sizes, img)
+	}
+
+	var variants []Variant
+	for _, format := range formats {
+		for _, sized := range sizes {
+			v, err := encode(sized, format)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, v)
+		}
+	}
+	return variants, nil
+}
+
+// resize scales img to the given width keeping the aspect ratio.
+func resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, format string) (Variant, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatWebP:
+		err = encodeWebP(&buf, img)
+	default:
+		err = fmt.Errorf("imaging: unknown variant format %q", format)
+	}
+	if err != nil {
+		return Variant{}, err
+	}
+	bounds := img.Bounds()
+	return Variant{
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Format: format,
+		Data:   buf.Bytes(),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func gradient(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	return img
+}
+
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		want   [][2]int
+	}{
+		{"large", 2000, 1000, [][2]int{{320, 160}, {800, 400}, {1600, 800}}},
+		{"medium", 800, 600, [][2]int{{320, 240}}},
+		{"small", 200, 100, [][2]int{{200, 100}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, err := Variants(gradient(tt.width, tt.height), Formats)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(variants) != len(tt.want)*len(Formats) {
+				t.Fatalf("got %d variants, want %d", len(variants), len(tt.want)*len(Formats))
+			}
+			for i, v := range variants {
+				format := Formats[i/len(tt.want)]
+				size := tt.want[i%len(tt.want)]
+				if v.Format != format || v.Width != size[0] || v.Height != size[1] {
+					t.Errorf("variant %d = %s %dx%d, want %s %dx%d", i, v.Format, v.Width, v.Height, format, size[0], size[1])
+				}
+				cfg, decoded, err := image.DecodeConfig(bytes.NewReader(v.Data))
+				if err != nil {
+					t.Fatalf("variant %d doesn't decode: %v", i, err)
+				}
+				if decoded != format || cfg.Width != size[0] || cfg.Height != size[1] {
+					t.Errorf("variant %d decodes as %s %dx%d", i, decoded, cfg.Width, cfg.Height)
+				}
+				if v.MimeType() != "image/"+format {
+					t.Errorf("variant %d MimeType = %s", i, v.MimeType())
+				}
+			}
+		})
+	}
+}
+
+func TestVariantsWebP(t *testing.T) {
+	_, err := Variants(gradient(10, 10), []string{FormatWebP})
+	if webpSupported && err != nil {
+		t.Fatal(err)
+	}
+	if !webpSupported && err == nil {
+		t.Fatal("WebP encoded without the webp build tag")
+	}
+}
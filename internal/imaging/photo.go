@@ -0,0 +1,293 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"time"
+
+	"admin-api/internal/filetype"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"golang.org/x/image/draw"
+)
+
+// sanitizedQuality is used when a photo has to be re-encoded to apply its
+// orientation; it is higher than jpegQuality because the result replaces the
+// original.
+const sanitizedQuality = 92
+
+// Photo is an uploaded image ready to be published: rotated upright and
+// stripped of EXIF, XMP and IPTC blocks that may carry GPS coordinates or
+// device details.
+type Photo struct {
+	// Image is the decoded picture after orientation was applied.
+	Image image.Image
+	// Data and MimeType are the sanitized file to store instead of the upload.
+	Data     []byte
+	MimeType string
+	Width    int
+	Height   int
+	// Orientation is the EXIF orientation (1–8) of the original, 0 if absent.
+	Orientation int
+	// TakenAt is the EXIF DateTimeOriginal, nil if unknown.
+	TakenAt *time.Time
+}
+
+// ErrCorrupt is returned when image data cannot be decoded.
+var ErrCorrupt = errors.New("imaging: cannot decode image")
+
+// Prepare reads the metadata of an image, applies its orientation and builds
+// a copy without metadata. Upright JPEG, PNG and WebP files are cleaned
+// losslessly by dropping metadata segments; rotated ones are re-encoded. GIF
+// has no EXIF and is kept as is. Images over maxPixels fail with ErrTooLarge.
+func Prepare(data []byte, mimeType string, maxPixels int64) (*Photo, error) {
+	img, err := Decode(data, maxPixels)
+	if err != nil {
+		return nil, err
+	}
+
+	photo := &Photo{MimeType: mimeType}
+	if x := readExif(data, mimeType); x != nil {
+		photo.Orientation = orientationOf(x)
+		if t, err := x.DateTime(); err == nil {
+			photo.TakenAt = &t
+		}
+	}
+
+	rotate := photo.Orientation > 1 && photo.Orientation <= 8
+	if rotate {
+		img = orient(img, photo.Orientation)
+	}
+	photo.Image = img
+	photo.Width, photo.Height = img.Bounds().Dx(), img.Bounds().Dy()
+
+	switch {
+	case mimeType == filetype.GIF:
+		photo.Data = data
+	case rotate && mimeType == filetype.PNG:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		photo.Data = buf.Bytes()
+	case rotate:
+		// Кодировщик WebP есть не в каждой сборке, поэтому повёрнутое фото
+		// сохраняется как JPEG.
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: sanitizedQuality}); err != nil {
+			return nil, err
+		}
+		photo.Data = buf.Bytes()
+		photo.MimeType = filetype.JPEG
+	case mimeType == filetype.JPEG:
+		photo.Data, err = stripJPEG(data)
+	case mimeType == filetype.PNG:
+		photo.Data, err = stripPNG(data)
+	case mimeType == filetype.WebP:
+		photo.Data, err = stripWebP(data)
+	default:
+		photo.Data = data
+	}
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return photo, nil
+}
+
+func readExif(data []byte, mimeType string) *exif.Exif {
+	raw := data
+	switch mimeType {
+	case filetype.JPEG:
+	case filetype.WebP:
+		if raw = webpChunk(data, "EXIF"); raw == nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	x, err := exif.Decode(bytes.NewReader(raw))
+	if x == nil || (err != nil && exif.IsCriticalError(err)) {
+		return nil
+	}
+	return x
+}
+
+func orientationOf(x *exif.Exif) int {
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return 0
+	}
+	o, err := tag.Int(0)
+	if err != nil {
+		return 0
+	}
+	return o
+}
+
+// orient returns img transformed so that EXIF orientation o becomes 1. The
+// pixels are moved directly between Pix slices, four bytes at a time.
+func orient(img image.Image, o int) image.Image {
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch o {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			i := dy*dst.Stride + dx*4
+			copy(dst.Pix[i:i+4], row[x*4:x*4+4])
+		}
+	}
+	return dst
+}
+
+// toRGBA returns img as *image.RGBA with its origin at (0, 0), converting
+// only when needed.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	return rgba
+}
+
+// stripJPEG removes APP1 (EXIF, XMP) and APP13 (IPTC) segments. Everything
+// from the start of scan on is copied untouched.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrCorrupt
+	}
+	out := append(make([]byte, 0, len(data)), data[:2]...)
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, ErrCorrupt
+		}
+		marker := data[i+1]
+		if marker == 0xDA { // SOS: дальше идут сжатые данные
+			break
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + size
+		if size < 2 || end > len(data) {
+			return nil, ErrCorrupt
+		}
+		if marker != 0xE1 && marker != 0xED {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return append(out, data[i:]...), nil
+}
+
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true, "tIME": true}
+
+// stripPNG drops textual and EXIF chunks.
+func stripPNG(data []byte) ([]byte, error) {
+	const sigLen = 8
+	if len(data) < sigLen {
+		return nil, ErrCorrupt
+	}
+	out := append(make([]byte, 0, len(data)), data[:sigLen]...)
+	for i := sigLen; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrCorrupt
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrCorrupt
+		}
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their flags in VP8X.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrCorrupt
+	}
+	out := append(make([]byte, 0, len(data)), data[:12]...)
+	bad := false
+	err := walkWebP(data, func(fourcc string, chunk []byte) {
+		switch fourcc {
+		case "EXIF", "XMP ":
+			return
+		case "VP8X":
+			if len(chunk) < 18 {
+				bad = true
+				return
+			}
+			start := len(out)
+			out = append(out, chunk...)
+			out[start+8] &^= 0x08 | 0x04
+			return
+		}
+		out = append(out, chunk...)
+	})
+	if err != nil || bad {
+		return nil, ErrCorrupt
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// webpChunk returns the payload of the first chunk with the given FourCC.
+func webpChunk(data []byte, fourcc string) []byte {
+	var payload []byte
+	walkWebP(data, func(cc string, chunk []byte) {
+		if cc == fourcc && payload == nil {
+			payload = chunk[8 : 8+binary.LittleEndian.Uint32(chunk[4:])]
+		}
+	})
+	return payload
+}
+
+// walkWebP calls fn for every chunk including its header and padding.
+func walkWebP(data []byte, fn func(fourcc string, chunk []byte)) error {
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return ErrCorrupt
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return ErrCorrupt
+		}
+		fn(string(data[i:i+4]), data[i:end])
+		i = end
+	}
+	return nil
+}
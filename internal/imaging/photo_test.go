@@ -0,0 +1,341 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+
+	"admin-api/internal/filetype"
+)
+
+// gpsMarker is the latitude written into the test EXIF; no sanitized file may
+// contain it.
+var gpsMarker = []byte("GPSLAT55751244")
+
+// tiffExif builds a little-endian TIFF block with the orientation, DateTime
+// and a GPS IFD holding the coordinates and a GPSMapDatum string set to
+// gpsMarker.
+func tiffExif(orientation int) []byte {
+	le := binary.LittleEndian
+	var b []byte
+	u16 := func(v int) { b = le.AppendUint16(b, uint16(v)) }
+	u32 := func(v int) { b = le.AppendUint32(b, uint32(v)) }
+	entry := func(tag, typ, count, value int) { u16(tag); u16(typ); u32(count); u32(value) }
+
+	const (
+		ifd0     = 8
+		ifd0Size = 2 + 3*12 + 4
+		dateOff  = ifd0 + ifd0Size
+		gpsIFD   = dateOff + 20
+		gpsSize  = 2 + 5*12 + 4
+		latOff   = gpsIFD + gpsSize
+		longOff  = latOff + 24
+		datumOff = longOff + 24
+	)
+	b = append(b, 'I', 'I')
+	u16(42)
+	u32(ifd0)
+
+	u16(3)
+	entry(0x0112, 3, 1, orientation) // Orientation, SHORT stored inline
+	entry(0x0132, 2, 20, dateOff)    // DateTime
+	entry(0x8825, 4, 1, gpsIFD)      // GPS IFD pointer
+	u32(0)
+	b = append(b, "2024:07:01 12:30:00\x00"...)
+
+	u16(5)
+	entry(0x0001, 2, 2, int(le.Uint32([]byte{'N', 0, 0, 0}))) // GPSLatitudeRef "N"
+	entry(0x0002, 5, 3, latOff)                               // GPSLatitude
+	entry(0x0003, 2, 2, int(le.Uint32([]byte{'E', 0, 0, 0}))) // GPSLongitudeRef "E"
+	entry(0x0004, 5, 3, longOff)                              // GPSLongitude
+	entry(0x0012, 2, len(gpsMarker)+1, datumOff)              // GPSMapDatum
+	u32(0)
+	for _, v := range []int{55, 1, 45, 1, 3, 1, 37, 1, 37, 1, 4, 1} {
+		u32(v)
+	}
+	b = append(b, gpsMarker...)
+	return append(b, 0)
+}
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(40 * x), uint8(40 * y), 200, 255})
+		}
+	}
+	return img
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// jpegWithMetadata returns a 6×4 JPEG with EXIF, XMP and IPTC segments
+// between SOI and the encoder's own segments.
+func jpegWithMetadata(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(6, 4), &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	out := append([]byte{}, plain[:2]...)
+	out = append(out, jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiffExif(orientation)...))...)
+	out = append(out, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>"+string(gpsMarker)+"</x:xmpmeta>"))...)
+	out = append(out, jpegSegment(0xED, []byte("Photoshop 3.0\x008BIM"+string(gpsMarker)))...)
+	return append(out, plain[2:]...)
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// pngWithMetadata inserts eXIf, tEXt, iTXt and tIME chunks after IHDR.
+func pngWithMetadata(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(5, 3)); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+	ihdrEnd := 8 + 12 + 13
+
+	out := append([]byte{}, plain[:ihdrEnd]...)
+	out = append(out, pngChunk("eXIf", tiffExif(1))...)
+	out = append(out, pngChunk("tEXt", []byte("Comment\x00"+string(gpsMarker)))...)
+	out = append(out, pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta>"+string(gpsMarker)+"</x:xmpmeta>"))...)
+	out = append(out, pngChunk("tIME", []byte{0x07, 0xE8, 7, 1, 12, 30, 0})...)
+	return append(out, plain[ihdrEnd:]...)
+}
+
+func webpChunkOf(fourcc string, payload []byte) []byte {
+	chunk := append([]byte(fourcc), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(payload)))
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// webpWithMetadata wraps testdata/gopher.lossless.webp into the extended
+// format with VP8X, EXIF and XMP chunks.
+func webpWithMetadata(t *testing.T, orientation int) ([]byte, image.Config) {
+	t.Helper()
+	simple, err := os.ReadFile("testdata/gopher.lossless.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(simple))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04 // EXIF, XMP
+	put24 := func(b []byte, v int) { b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16) }
+	put24(vp8x[4:], cfg.Width-1)
+	put24(vp8x[7:], cfg.Height-1)
+
+	body := []byte("WEBP")
+	body = append(body, webpChunkOf("VP8X", vp8x)...)
+	body = append(body, simple[12:]...)
+	body = append(body, webpChunkOf("EXIF", tiffExif(orientation))...)
+	body = append(body, webpChunkOf("XMP ", []byte("<x:xmpmeta>"+string(gpsMarker)+"</x:xmpmeta>"))...)
+
+	out := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+	return append(out, body...), cfg
+}
+
+func checkClean(t *testing.T, photo *Photo, forbidden ...string) {
+	t.Helper()
+	if bytes.Contains(photo.Data, gpsMarker) {
+		t.Error("sanitized file still contains the GPS data")
+	}
+	for _, s := range forbidden {
+		if bytes.Contains(photo.Data, []byte(s)) {
+			t.Errorf("sanitized file still contains %q", s)
+		}
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(photo.Data))
+	if err != nil {
+		t.Fatalf("sanitized file doesn't decode: %v", err)
+	}
+	if "image/"+format != photo.MimeType || cfg.Width != photo.Width || cfg.Height != photo.Height {
+		t.Errorf("sanitized file is %s %dx%d, photo says %s %dx%d", format, cfg.Width, cfg.Height, photo.MimeType, photo.Width, photo.Height)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(photo.Data)); err != nil {
+		t.Errorf("sanitized file doesn't decode: %v", err)
+	}
+}
+
+func TestPrepareJPEG(t *testing.T) {
+	data := jpegWithMetadata(t, 1)
+	if lat, _, err := readExif(data, filetype.JPEG).LatLong(); err != nil || lat < 55.7 || lat > 55.8 {
+		t.Fatalf("fixture GPS = %v, %v; want latitude 55.75", lat, err)
+	}
+	photo, err := Prepare(data, filetype.JPEG, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClean(t, photo, "Exif\x00\x00", "xmpmeta", "Photoshop 3.0")
+	if photo.MimeType != filetype.JPEG || photo.Width != 6 || photo.Height != 4 || photo.Orientation != 1 {
+		t.Errorf("photo = %s %dx%d orientation %d", photo.MimeType, photo.Width, photo.Height, photo.Orientation)
+	}
+	want := time.Date(2024, 7, 1, 12, 30, 0, 0, time.Local)
+	if photo.TakenAt == nil || !photo.TakenAt.Equal(want) {
+		t.Errorf("TakenAt = %v, want %v", photo.TakenAt, want)
+	}
+	// An upright photo is not re-encoded: the compressed data stays the same.
+	if !bytes.HasSuffix(data, photo.Data[2:]) {
+		t.Error("upright JPEG was re-encoded instead of stripped")
+	}
+}
+
+func TestPrepareRotatedJPEG(t *testing.T) {
+	photo, err := Prepare(jpegWithMetadata(t, 6), filetype.JPEG, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClean(t, photo, "Exif\x00\x00", "xmpmeta", "Photoshop 3.0")
+	if photo.Width != 4 || photo.Height != 6 || photo.Orientation != 6 {
+		t.Errorf("photo = %dx%d orientation %d, want 4x6 orientation 6", photo.Width, photo.Height, photo.Orientation)
+	}
+}
+
+func TestPreparePNG(t *testing.T) {
+	photo, err := Prepare(pngWithMetadata(t), filetype.PNG, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClean(t, photo, "eXIf", "tEXt", "iTXt", "tIME", "xmpmeta")
+	if photo.MimeType != filetype.PNG || photo.Width != 5 || photo.Height != 3 {
+		t.Errorf("photo = %s %dx%d", photo.MimeType, photo.Width, photo.Height)
+	}
+}
+
+func TestPrepareWebP(t *testing.T) {
+	data, cfg := webpWithMetadata(t, 1)
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("fixture doesn't decode: %v", err)
+	}
+
+	photo, err := Prepare(data, filetype.WebP, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClean(t, photo, "EXIF", "XMP ", "xmpmeta")
+	if photo.MimeType != filetype.WebP || photo.Width != cfg.Width || photo.Height != cfg.Height {
+		t.Errorf("photo = %s %dx%d", photo.MimeType, photo.Width, photo.Height)
+	}
+	if photo.TakenAt == nil {
+		t.Error("TakenAt not read from the EXIF chunk")
+	}
+
+	out := photo.Data
+	if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(out)-8)
+	}
+	if string(out[12:16]) != "VP8X" {
+		t.Fatalf("first chunk = %q, want VP8X", out[12:16])
+	}
+	if flags := out[20]; flags != 0 {
+		t.Errorf("VP8X flags = %#x, want EXIF and XMP cleared", flags)
+	}
+}
+
+func TestPrepareRotatedWebP(t *testing.T) {
+	data, cfg := webpWithMetadata(t, 8)
+	photo, err := Prepare(data, filetype.WebP, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClean(t, photo, "EXIF", "xmpmeta")
+	if photo.MimeType != filetype.JPEG || photo.Width != cfg.Height || photo.Height != cfg.Width {
+		t.Errorf("photo = %s %dx%d, want a %dx%d JPEG", photo.MimeType, photo.Width, photo.Height, cfg.Height, cfg.Width)
+	}
+}
+
+func TestStripCorrupt(t *testing.T) {
+	webp, _ := webpWithMetadata(t, 1)
+	shortVP8X := append([]byte("RIFF\x0c\x00\x00\x00WEBP"), webpChunkOf("VP8X", nil)...)
+	tests := []struct {
+		name  string
+		strip func([]byte) ([]byte, error)
+		data  []byte
+	}{
+		{"jpeg without SOI", stripJPEG, []byte{0xFF, 0xD9}},
+		{"jpeg segment past end", stripJPEG, []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x10, 0x00, 0x00}},
+		{"jpeg junk between segments", stripJPEG, []byte{0xFF, 0xD8, 0x00, 0x00, 0x00, 0x00}},
+		{"png chunk past end", stripPNG, append(pngWithMetadata(t)[:40], 0xFF, 0xFF, 0xFF, 0xFF, 'I', 'D', 'A', 'T')},
+		{"webp chunk past end", stripWebP, webp[:len(webp)-5]},
+		{"webp short VP8X", stripWebP, shortVP8X},
+		{"not webp", stripWebP, []byte("RIFF\x04\x00\x00\x00WAVE")},
+	}
+	for _, tt := range tests {
+		if _, err := tt.strip(tt.data); err != ErrCorrupt {
+			t.Errorf("%s: err = %v, want ErrCorrupt", tt.name, err)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3×2 picture with distinct pixels:
+	//
+	//	a b c
+	//	d e f
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i, r := range "abcdef" {
+		src.Set(i%3, i/3, color.RGBA{uint8(r), 0, 0, 255})
+	}
+
+	// Rows of the upright picture for each EXIF orientation of the source.
+	tests := map[int][]string{
+		1: {"abc", "def"},
+		2: {"cba", "fed"},
+		3: {"fed", "cba"},
+		4: {"def", "abc"},
+		5: {"ad", "be", "cf"},
+		6: {"da", "eb", "fc"},
+		7: {"fc", "eb", "da"},
+		8: {"cf", "be", "ad"},
+	}
+	for o := 1; o <= 8; o++ {
+		got := orient(src, o)
+		b := got.Bounds()
+		var rows []string
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			var row []byte
+			for x := b.Min.X; x < b.Max.X; x++ {
+				r, _, _, _ := got.At(x, y).RGBA()
+				row = append(row, byte(r>>8))
+			}
+			rows = append(rows, string(row))
+		}
+		if len(rows) != len(tests[o]) {
+			t.Errorf("orientation %d: rows %q, want %q", o, rows, tests[o])
+			continue
+		}
+		for i := range rows {
+			if rows[i] != tests[o][i] {
+				t.Errorf("orientation %d: rows %q, want %q", o, rows, tests[o])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build webp && cgo
+
+package imaging
+
+import (
+	"image"
+	"io"
+
+	"github.com/chai2010/webp"
+)
+
+const webpSupported = true
+
+func encodeWebP(w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, &webp.Options{Quality: webpQuality})
+}
@@ -0,0 +1,17 @@
+//go:build !webp || !cgo
+
+package imaging
+
+import (
+	"errors"
+	"image"
+	"io"
+)
+
+// Without the webp tag there is no WebP encoder: golang.org/x/image can only
+// decode WebP, so variants are made in JPEG alone.
+const webpSupported = false
+
+func encodeWebP(w io.Writer, img image.Image) error {
+	return errors.New("imaging: built without WebP support, rebuild with -tags webp")
+}
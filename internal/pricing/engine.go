@@ -0,0 +1,89 @@
+package pricing
+
+import (
+	"errors"
+	"time"
+
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+// Quote is the price of a service on a given day and where it came from:
+// either a calendar rule or a row of the base price list.
+type Quote struct {
+	Date     models.Date `json:"date"`
+	Label    string      `json:"label"`
+	Amount   int64       `json:"amount"`
+	Currency string      `json:"currency"`
+	Unit     string      `json:"unit"`
+	RuleID   *int        `json:"rule_id"`
+	PriceID  *int        `json:"price_id"`
+}
+
+// Effective returns the price on day. The matching rule with the highest
+// priority wins, ties go to the most recently created rule. Without a
+// matching rule the cheapest base price in season is used. ok is false when
+// neither applies.
+func Effective(prices []models.ServicePrices, rules []models.PriceRules, day models.Date) (Quote, bool) {
+	var best *models.PriceRules
+	for i := range rules {
+		r := &rules[i]
+		if !r.Matches(day) {
+			continue
+		}
+		if best == nil || r.Priority > best.Priority || (r.Priority == best.Priority && r.ID > best.ID) {
+			best = r
+		}
+	}
+	if best != nil {
+		return Quote{
+			Date:     day,
+			Label:    best.Label,
+			Amount:   best.Amount,
+			Currency: best.Currency,
+			Unit:     best.Unit,
+			RuleID:   &best.ID,
+		}, true
+	}
+
+	var base *models.ServicePrices
+	for i := range prices {
+		p := &prices[i]
+		if p.InSeason(day) && (base == nil || p.Amount < base.Amount) {
+			base = p
+		}
+	}
+	if base == nil {
+		return Quote{}, false
+	}
+	return Quote{
+		Date:     day,
+		Label:    base.Label,
+		Amount:   base.Amount,
+		Currency: base.Currency,
+		Unit:     base.Unit,
+		PriceID:  &base.ID,
+	}, true
+}
+
+// Today returns the current date at the resort: in the time zone of the
+// primary location's opening hours, so prices switch at local midnight
+// rather than the server's. Without a time zone the server's one is used.
+func Today(db *gorm.DB, now time.Time) (models.Date, error) {
+	var location models.Locations
+	err := db.Select("opening_hours").Where("is_primary").Take(&location).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Date{}, err
+	}
+	return localDate(location.OpeningHours, now), nil
+}
+
+func localDate(h *models.OpeningHours, now time.Time) models.Date {
+	if h != nil && h.Timezone != "" {
+		if tz, err := time.LoadLocation(h.Timezone); err == nil {
+			now = now.In(tz)
+		}
+	}
+	return models.DateOf(now)
+}
@@ -0,0 +1,115 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"admin-api/models"
+)
+
+func date(s string) models.Date {
+	d, err := models.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func datePtr(s string) *models.Date {
+	d := date(s)
+	return &d
+}
+
+const weekend = models.Weekdays(1<<5 | 1<<6)
+
+func TestEffective(t *testing.T) {
+	rules := []models.PriceRules{
+		{ID: 1, Label: "Выходные", Weekdays: weekend, Priority: 1, Amount: 400000},
+		{ID: 2, Label: "Новогодние", DateFrom: datePtr("2026-12-31"), DateTo: datePtr("2027-01-08"), Priority: 10, Amount: 900000},
+		{ID: 3, Label: "1 января", DateFrom: datePtr("2027-01-01"), DateTo: datePtr("2027-01-01"), Priority: 10, Amount: 950000},
+		{ID: 4, Label: "Новая цена", DateFrom: datePtr("2027-02-01"), Amount: 350000},
+	}
+	prices := []models.ServicePrices{
+		{ID: 10, Label: "Базовая", Amount: 300000},
+		{ID: 11, Label: "Лето", Amount: 250000, SeasonFrom: datePtr("2027-06-01"), SeasonTo: datePtr("2027-08-31")},
+		{ID: 12, Label: "Дорогая", Amount: 500000},
+	}
+
+	tests := []struct {
+		name      string
+		prices    []models.ServicePrices
+		rules     []models.PriceRules
+		day       string
+		wantOK    bool
+		wantRule  int
+		wantPrice int
+		amount    int64
+	}{
+		{"weekday without rules", prices, rules, "2026-12-30", true, 0, 10, 300000},
+		{"first day of a period", prices, rules, "2026-12-31", true, 2, 0, 900000},
+		{"equal priority, later rule wins", prices, rules, "2027-01-01", true, 3, 0, 950000},
+		{"higher priority beats weekend", prices, rules, "2027-01-02", true, 2, 0, 900000},
+		{"last day of a period", prices, rules, "2027-01-08", true, 2, 0, 900000},
+		{"day after a period", prices, rules, "2027-01-09", true, 1, 0, 400000},
+		{"weekday after a period", prices, rules, "2027-01-11", true, 0, 10, 300000},
+		{"day before an open-ended rule", prices, rules, "2027-01-29", true, 0, 10, 300000},
+		{"open-ended rule starts", prices, rules, "2027-02-01", true, 4, 0, 350000},
+		{"before the season", prices, nil, "2027-05-31", true, 0, 10, 300000},
+		{"season starts", prices, nil, "2027-06-01", true, 0, 11, 250000},
+		{"season ends", prices, nil, "2027-08-31", true, 0, 11, 250000},
+		{"after the season", prices, nil, "2027-09-01", true, 0, 10, 300000},
+		{"only out-of-season prices", prices[1:2], nil, "2027-01-11", false, 0, 0, 0},
+		{"nothing", nil, nil, "2027-01-11", false, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, ok := Effective(tt.prices, tt.rules, date(tt.day))
+			if ok != tt.wantOK {
+				t.Fatalf("Effective(%s) ok = %v, want %v", tt.day, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if quote.Amount != tt.amount || quote.Date != date(tt.day) {
+				t.Errorf("Effective(%s) = %d on %s, want %d", tt.day, quote.Amount, quote.Date, tt.amount)
+			}
+			if got := idOf(quote.RuleID); got != tt.wantRule {
+				t.Errorf("rule_id = %d, want %d", got, tt.wantRule)
+			}
+			if got := idOf(quote.PriceID); got != tt.wantPrice {
+				t.Errorf("price_id = %d, want %d", got, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func idOf(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+func TestLocalDate(t *testing.T) {
+	// 22:30 UTC is already the next day in Moscow.
+	now := time.Date(2026, 10, 17, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		hours *models.OpeningHours
+		want  string
+	}{
+		{"no opening hours", nil, "2026-10-17"},
+		{"no time zone", &models.OpeningHours{}, "2026-10-17"},
+		{"unknown time zone", &models.OpeningHours{Timezone: "Mars/Olympus"}, "2026-10-17"},
+		{"ahead of the server", &models.OpeningHours{Timezone: "Europe/Moscow"}, "2026-10-18"},
+		{"behind the server", &models.OpeningHours{Timezone: "America/New_York"}, "2026-10-17"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localDate(tt.hours, now); got != date(tt.want) {
+				t.Errorf("localDate = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
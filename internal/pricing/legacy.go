@@ -0,0 +1,151 @@
+package pricing
+
+// Package pricing works with the structured price list of services.
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"admin-api/models"
+)
+
+// ErrNoAmount means a legacy price line contains no number to take as the amount.
+var ErrNoAmount = errors.New("не найдена сумма")
+
+// ErrAmbiguous means a legacy price line contains several numbers, e.g.
+// "2 часа — 3000 ₽" or "1500–2000 ₽", and the price has to be entered by hand.
+var ErrAmbiguous = errors.New("несколько чисел, цену нужно указать вручную")
+
+// amountPattern matches "3000", "3 000", "3 000,50" or "3000.5"; spaces
+// between thousands may be regular or non-breaking.
+var amountPattern = regexp.MustCompile(`(\d{1,3}(?:[ \x{00A0}\x{202F}]\d{3})+|\d+)(?:[.,](\d{1,2}))?`)
+
+var (
+	currencyHints = []struct {
+		currency string
+		hints    []string
+	}{
+		{"USD", []string{"$", "usd", "долл"}},
+		{"EUR", []string{"€", "eur", "евро"}},
+		{models.CurrencyRUB, []string{"₽", "руб", "rub", "р."}},
+	}
+	unitHints = []struct {
+		unit  string
+		hints []string
+	}{
+		{models.UnitHour, []string{"час"}},
+		{models.UnitNight, []string{"ноч"}},
+		{models.UnitPerson, []string{"чел", "гост", "персон"}},
+		{models.UnitDay, []string{"сут", "день", "дня", "дней"}},
+	}
+)
+
+// ParseLegacy turns the free-form Services.Prices text, e.g. "от 3000 ₽ в час",
+// into price rows. Lines and ";"-separated parts become separate rows labelled
+// with their original text. A part must contain exactly one number: with
+// several of them ("2 часа — 3000 ₽", "1500–2000 ₽") it fails with
+// ErrAmbiguous instead of guessing. A part without a currency sign is assumed
+// to be in rubles.
+func ParseLegacy(text string) ([]models.ServicePrices, error) {
+	var prices []models.ServicePrices
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		price, err := parseLine(part)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", part, err)
+		}
+		prices = append(prices, price)
+	}
+	if len(prices) == 0 {
+		return nil, ErrNoAmount
+	}
+	return prices, nil
+}
+
+func parseLine(line string) (models.ServicePrices, error) {
+	lower := strings.ToLower(line)
+	matches := amountPattern.FindAllStringSubmatchIndex(line, -1)
+	switch {
+	case len(matches) == 0:
+		return models.ServicePrices{}, ErrNoAmount
+	case len(matches) > 1:
+		numbers := make([]string, len(matches))
+		hint := ""
+		for i, m := range matches {
+			numbers[i] = line[m[0]:m[1]]
+			if nextToCurrency(lower, m[0], m[1]) {
+				hint = fmt.Sprintf(", у знака валюты — %s", numbers[i])
+			}
+		}
+		return models.ServicePrices{}, fmt.Errorf("%w (%s%s)", ErrAmbiguous, strings.Join(numbers, ", "), hint)
+	}
+
+	m := matches[0]
+	whole, err := strconv.ParseInt(strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, line[m[2]:m[3]]), 10, 64)
+	if err != nil {
+		return models.ServicePrices{}, err
+	}
+	fraction := int64(0)
+	if m[4] >= 0 {
+		digits := line[m[4]:m[5]]
+		fraction, _ = strconv.ParseInt(digits, 10, 64)
+		if len(digits) == 1 {
+			fraction *= 10
+		}
+	}
+
+	return models.ServicePrices{
+		Label:    line,
+		Amount:   whole*100 + fraction,
+		Currency: detectCurrency(lower),
+		Unit:     detectUnit(lower),
+	}, nil
+}
+
+// nextToCurrency reports whether a currency sign or word directly follows or
+// precedes lower[start:end], as in "3000 ₽" or "$50".
+func nextToCurrency(lower string, start, end int) bool {
+	before := strings.TrimRight(lower[:start], " \u00a0")
+	after := strings.TrimLeft(lower[end:], " \u00a0")
+	for _, c := range currencyHints {
+		for _, hint := range c.hints {
+			if strings.HasPrefix(after, hint) || strings.HasSuffix(before, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func detectCurrency(lower string) string {
+	for _, c := range currencyHints {
+		for _, hint := range c.hints {
+			if strings.Contains(lower, hint) {
+				return c.currency
+			}
+		}
+	}
+	return models.CurrencyRUB
+}
+
+func detectUnit(lower string) string {
+	for _, u := range unitHints {
+		for _, hint := range u.hints {
+			if strings.Contains(lower, hint) {
+				return u.unit
+			}
+		}
+	}
+	return models.UnitFixed
+}
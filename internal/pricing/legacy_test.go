@@ -0,0 +1,107 @@
+package pricing
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"admin-api/models"
+)
+
+func TestParseLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []models.ServicePrices
+		wantErr error
+		// wantHint is expected in the error text of ambiguous lines.
+		wantHint string
+	}{
+		{
+			name: "hour",
+			text: "от 3000 ₽ в час",
+			want: []models.ServicePrices{{Label: "от 3000 ₽ в час", Amount: 300000, Currency: models.CurrencyRUB, Unit: models.UnitHour}},
+		},
+		{
+			name: "thousands and kopecks",
+			text: "3 000,50 руб.",
+			want: []models.ServicePrices{{Label: "3 000,50 руб.", Amount: 300050, Currency: models.CurrencyRUB, Unit: models.UnitFixed}},
+		},
+		{
+			name: "non-breaking space and one decimal digit",
+			text: "12 500.5 р. за ночь",
+			want: []models.ServicePrices{{Label: "12 500.5 р. за ночь", Amount: 1250050, Currency: models.CurrencyRUB, Unit: models.UnitNight}},
+		},
+		{
+			name: "сутки are a day",
+			text: "5000 руб./сутки",
+			want: []models.ServicePrices{{Label: "5000 руб./сутки", Amount: 500000, Currency: models.CurrencyRUB, Unit: models.UnitDay}},
+		},
+		{
+			name: "other currency, per person",
+			text: "$50 с человека",
+			want: []models.ServicePrices{{Label: "$50 с человека", Amount: 5000, Currency: "USD", Unit: models.UnitPerson}},
+		},
+		{
+			name: "rubles by default",
+			text: "Прокат 700",
+			want: []models.ServicePrices{{Label: "Прокат 700", Amount: 70000, Currency: models.CurrencyRUB, Unit: models.UnitFixed}},
+		},
+		{
+			name: "lines and parts",
+			text: "Будни 2000 ₽ в день;\n\n Выходные 2500 ₽ в день ",
+			want: []models.ServicePrices{
+				{Label: "Будни 2000 ₽ в день", Amount: 200000, Currency: models.CurrencyRUB, Unit: models.UnitDay},
+				{Label: "Выходные 2500 ₽ в день", Amount: 250000, Currency: models.CurrencyRUB, Unit: models.UnitDay},
+			},
+		},
+		{
+			name:     "duration before the price",
+			text:     "2 часа — 3000 ₽",
+			wantErr:  ErrAmbiguous,
+			wantHint: "у знака валюты — 3000",
+		},
+		{
+			name:     "range",
+			text:     "1500–2000 ₽",
+			wantErr:  ErrAmbiguous,
+			wantHint: "у знака валюты — 2000",
+		},
+		{
+			name:    "one bad part fails the whole text",
+			text:    "Баня 1500 ₽ в час\nс 10 до 22",
+			wantErr: ErrAmbiguous,
+		},
+		{
+			name:    "no number",
+			text:    "по договорённости",
+			wantErr: ErrNoAmount,
+		},
+		{
+			name:    "only separators",
+			text:    " ; \n",
+			wantErr: ErrNoAmount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLegacy(tt.text)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseLegacy(%q) error = %v, want %v", tt.text, err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantHint) {
+					t.Errorf("error %q does not contain %q", err, tt.wantHint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLegacy(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLegacy(%q) =\n %+v\nwant\n %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"admin-api/models"
+
+	"gorm.io/gorm"
+)
+
+type MigrateOptions struct {
+	// DryRun parses and reports without writing anything.
+	DryRun bool
+}
+
+// Failure is a service whose legacy price text could not be parsed.
+type Failure struct {
+	ServiceID int
+	Eng       string
+	Text      string
+	Err       error
+}
+
+type MigrateReport struct {
+	// Migrated counts services that got price rows, Created the rows themselves.
+	Migrated int
+	Created  int
+	// Skipped counts services that already have a price list; they are left alone.
+	Skipped int
+	Failed  []Failure
+}
+
+// MigrateLegacy fills the price list of every service that has legacy Prices
+// text and no structured prices yet. The legacy text is kept, so the command
+// is safe to run again after fixing the reported services by hand.
+func MigrateLegacy(db *gorm.DB, opts MigrateOptions) (MigrateReport, error) {
+	var report MigrateReport
+
+	var services []models.Services
+	if err := db.Preload("PriceList").Where("prices <> ''").Order("id").Find(&services).Error; err != nil {
+		return report, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, service := range services {
+			if len(service.PriceList) > 0 {
+				report.Skipped++
+				continue
+			}
+			prices, err := ParseLegacy(service.Prices)
+			if err != nil {
+				report.Failed = append(report.Failed, Failure{service.ID, service.Eng, service.Prices, err})
+				continue
+			}
+			for i := range prices {
+				prices[i].ServiceID = service.ID
+			}
+			if !opts.DryRun {
+				if err := tx.Create(&prices).Error; err != nil {
+					return err
+				}
+			}
+			report.Migrated++
+			report.Created += len(prices)
+		}
+		return nil
+	})
+	return report, err
+}
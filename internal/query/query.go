@@ -0,0 +1,341 @@
+package query
+
+// Package query turns list parameters from the URL into a GORM query so every
+// list endpoint supports the same pagination, sorting and filtering:
+//
+//	?limit=20&offset=40        page by offset
+//	?limit=20&cursor=<token>   page by the X-Next-Cursor of the previous page
+//	?sort=-taken_at,id         sort by whitelisted columns, "-" for descending
+//	?hidden=false              filter by whitelisted columns
+//
+// The total number of matching rows is reported separately so handlers can
+// keep returning plain JSON arrays.
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 500
+)
+
+// Kind is the type of a filter value.
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Bool
+)
+
+// Spec whitelists what a list endpoint may sort and filter by. Column names
+// double as parameter names, they match the JSON fields of the models.
+type Spec struct {
+	Sort    []string
+	Filters map[string]Kind
+	// Default is the sort used when ?sort is absent, e.g. "position,id".
+	Default string
+	// Key is a unique column appended to every sort so that pages are stable
+	// and cursors work. Without it cursors are rejected.
+	Key string
+}
+
+// Error is a problem with the request parameters, reported to the client as 400.
+type Error struct {
+	Param   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Param, e.Message)
+}
+
+// Page describes the rows around the returned slice.
+type Page struct {
+	Total int64
+	// Next is the cursor of the following page, empty on the last one.
+	Next string
+}
+
+type order struct {
+	column string
+	desc   bool
+}
+
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+// Find loads into dest (a pointer to a slice of models) the page described
+// by values. db may already carry conditions of the handler; scopes such as
+// Preload are applied to the page query only, not to the count.
+func Find(db *gorm.DB, values url.Values, spec Spec, dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) (Page, error) {
+	var page Page
+
+	limit, err := intParam(values, "limit", DefaultLimit)
+	if err != nil {
+		return page, err
+	}
+	if limit < 1 || limit > MaxLimit {
+		return page, &Error{"limit", fmt.Sprintf("должен быть от 1 до %d", MaxLimit)}
+	}
+	offset, err := intParam(values, "offset", 0)
+	if err != nil {
+		return page, err
+	}
+	if offset < 0 {
+		return page, &Error{"offset", "не может быть отрицательным"}
+	}
+
+	orders, sortKey, err := parseSort(values.Get("sort"), spec)
+	if err != nil {
+		return page, err
+	}
+
+	filtered := db.Model(dest)
+	for name, kind := range spec.Filters {
+		raw, ok := values[name]
+		if !ok {
+			continue
+		}
+		value, err := parseValue(raw[0], kind)
+		if err != nil {
+			return page, &Error{name, err.Error()}
+		}
+		filtered = filtered.Where(clause.Eq{Column: clause.Column{Name: name}, Value: value})
+	}
+
+	if err := filtered.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	tx := filtered.Session(&gorm.Session{}).Scopes(scopes...).Limit(limit)
+	if token := values.Get("cursor"); token != "" {
+		if offset > 0 {
+			return page, &Error{"cursor", "нельзя использовать вместе с offset"}
+		}
+		if spec.Key == "" {
+			return page, &Error{"cursor", "не поддерживается для этого списка"}
+		}
+		condition, err := after(token, sortKey, orders)
+		if err != nil {
+			return page, err
+		}
+		tx = tx.Where(condition)
+	} else {
+		tx = tx.Offset(offset)
+	}
+	for _, o := range orders {
+		tx = tx.Order(orderSQL(o))
+	}
+	if err := tx.Find(dest).Error; err != nil {
+		return page, err
+	}
+
+	if spec.Key != "" {
+		next, err := nextCursor(db, dest, limit, sortKey, orders)
+		if err != nil {
+			return page, err
+		}
+		page.Next = next
+	}
+	return page, nil
+}
+
+func intParam(values url.Values, name string, def int) (int, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &Error{name, "должен быть числом"}
+	}
+	return n, nil
+}
+
+// parseSort returns the sort columns with spec.Key appended and a canonical
+// form of them that is embedded into cursors.
+func parseSort(raw string, spec Spec) ([]order, string, error) {
+	fromRequest := raw != ""
+	if !fromRequest {
+		raw = spec.Default
+	}
+
+	var orders []order
+	seen := map[string]bool{}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		o := order{column: strings.TrimPrefix(field, "-"), desc: strings.HasPrefix(field, "-")}
+		if fromRequest && !contains(spec.Sort, o.column) {
+			return nil, "", &Error{"sort", fmt.Sprintf("нельзя сортировать по %q, доступны: %s", o.column, strings.Join(spec.Sort, ", "))}
+		}
+		if seen[o.column] {
+			continue
+		}
+		seen[o.column] = true
+		orders = append(orders, o)
+	}
+	if spec.Key != "" && !seen[spec.Key] {
+		orders = append(orders, order{column: spec.Key})
+	}
+
+	parts := make([]string, len(orders))
+	for i, o := range orders {
+		parts[i] = o.column
+		if o.desc {
+			parts[i] = "-" + o.column
+		}
+	}
+	return orders, strings.Join(parts, ","), nil
+}
+
+// orderSQL keeps NULLs last in both directions so that cursors can treat
+// them uniformly.
+func orderSQL(o order) string {
+	dir := "ASC"
+	if o.desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s NULLS LAST", quote(o.column), dir)
+}
+
+func parseValue(raw string, kind Kind) (interface{}, error) {
+	switch kind {
+	case Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается true или false")
+		}
+		return v, nil
+	case Int:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается число")
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
+
+// after builds the keyset condition "row comes after the cursor" for the
+// given sort, expanded as (a > x) OR (a = x AND b > y) OR ...
+func after(token, sortKey string, orders []order) (clause.Expression, error) {
+	invalid := &Error{"cursor", "неверный курсор"}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursor
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil || len(c.Values) != len(orders) {
+		return nil, invalid
+	}
+	if c.Sort != sortKey {
+		return nil, &Error{"cursor", "курсор получен для другой сортировки"}
+	}
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			if c.Values[i], err = n.Int64(); err != nil {
+				c.Values[i], _ = n.Float64()
+			}
+		}
+	}
+
+	var alternatives []clause.Expression
+	for i, o := range orders {
+		var and []clause.Expression
+		for j := 0; j < i; j++ {
+			and = append(and, equal(orders[j].column, c.Values[j]))
+		}
+		and = append(and, beyond(o, c.Values[i]))
+		alternatives = append(alternatives, clause.And(and...))
+	}
+	return clause.Or(alternatives...), nil
+}
+
+func equal(column string, v interface{}) clause.Expression {
+	if v == nil {
+		return clause.Expr{SQL: quote(column) + " IS NULL"}
+	}
+	return clause.Expr{SQL: quote(column) + " = ?", Vars: []interface{}{v}}
+}
+
+// beyond matches rows strictly after v in the direction of o. NULLs go last,
+// so nothing follows a NULL and every NULL follows a value.
+func beyond(o order, v interface{}) clause.Expression {
+	if v == nil {
+		return clause.Expr{SQL: "FALSE"}
+	}
+	op := ">"
+	if o.desc {
+		op = "<"
+	}
+	return clause.Expr{SQL: fmt.Sprintf("(%s %s ? OR %s IS NULL)", quote(o.column), op, quote(o.column)), Vars: []interface{}{v}}
+}
+
+// nextCursor encodes the sort values of the last row when the page is full.
+func nextCursor(db *gorm.DB, dest interface{}, limit int, sortKey string, orders []order) (string, error) {
+	rows := reflect.Indirect(reflect.ValueOf(dest))
+	if rows.Kind() != reflect.Slice || rows.Len() < limit || rows.Len() == 0 {
+		return "", nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return "", err
+	}
+	last := reflect.Indirect(rows.Index(rows.Len() - 1))
+
+	c := cursor{Sort: sortKey}
+	for _, o := range orders {
+		field := stmt.Schema.LookUpField(o.column)
+		if field == nil {
+			return "", fmt.Errorf("query: unknown column %q", o.column)
+		}
+		value, zero := field.ValueOf(context.Background(), last)
+		if zero && reflect.ValueOf(value).Kind() == reflect.Ptr {
+			value = nil
+		}
+		c.Values = append(c.Values, value)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// quote is safe because columns come from a Spec, never from the request.
+func quote(column string) string {
+	return `"` + column + `"`
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
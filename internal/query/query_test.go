@@ -0,0 +1,285 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID       int        `json:"id"`
+	Position int        `json:"position"`
+	Name     string     `json:"name"`
+	TakenAt  *time.Time `json:"taken_at"`
+	Hidden   bool       `json:"hidden"`
+}
+
+var spec = Spec{
+	Sort:    []string{"position", "id", "name", "taken_at"},
+	Filters: map[string]Kind{"hidden": Bool, "position": Int},
+	Default: "position",
+	Key:     "id",
+}
+
+// dryRun builds SQL for Postgres without connecting to it.
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		spec    Spec
+		want    []order
+		wantKey string
+		errName string
+	}{
+		{
+			name:    "default with key appended",
+			spec:    spec,
+			want:    []order{{column: "position"}, {column: "id"}},
+			wantKey: "position,id",
+		},
+		{
+			name:    "descending",
+			raw:     "-taken_at",
+			spec:    spec,
+			want:    []order{{column: "taken_at", desc: true}, {column: "id"}},
+			wantKey: "-taken_at,id",
+		},
+		{
+			name:    "key already present",
+			raw:     "-id",
+			spec:    spec,
+			want:    []order{{column: "id", desc: true}},
+			wantKey: "-id",
+		},
+		{
+			name:    "spaces and duplicates",
+			raw:     " name , -name ,",
+			spec:    spec,
+			want:    []order{{column: "name"}, {column: "id"}},
+			wantKey: "name,id",
+		},
+		{
+			name:    "default is not checked against the whitelist",
+			spec:    Spec{Sort: []string{"id"}, Default: "position,id", Key: "id"},
+			want:    []order{{column: "position"}, {column: "id"}},
+			wantKey: "position,id",
+		},
+		{
+			name:    "no key",
+			raw:     "name",
+			spec:    Spec{Sort: []string{"name"}},
+			want:    []order{{column: "name"}},
+			wantKey: "name",
+		},
+		{
+			name:    "column outside the whitelist",
+			raw:     "password_hash",
+			spec:    spec,
+			errName: "sort",
+		},
+		{
+			name:    "injection attempt",
+			raw:     `id"; DROP TABLE users; --`,
+			spec:    spec,
+			errName: "sort",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, key, err := parseSort(tt.raw, tt.spec)
+			if tt.errName != "" {
+				assertParamError(t, err, tt.errName)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || key != tt.wantKey {
+				t.Errorf("parseSort(%q) = %v, %q; want %v, %q", tt.raw, got, key, tt.want, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestOrderSQL(t *testing.T) {
+	tests := []struct {
+		order order
+		want  string
+	}{
+		{order{column: "position"}, `"position" ASC NULLS LAST`},
+		{order{column: "taken_at", desc: true}, `"taken_at" DESC NULLS LAST`},
+	}
+	for _, tt := range tests {
+		if got := orderSQL(tt.order); got != tt.want {
+			t.Errorf("orderSQL(%v) = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	db := dryRun(t)
+	taken := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		sort     string
+		last     item
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{
+			name:     "ascending int",
+			sort:     "position",
+			last:     item{ID: 7, Position: 3},
+			wantSQL:  `SELECT * FROM "items" WHERE ((("position" > $1 OR "position" IS NULL)) OR ("position" = $2 AND (("id" > $3 OR "id" IS NULL))))`,
+			wantVars: []interface{}{int64(3), int64(3), int64(7)},
+		},
+		{
+			name:     "descending time",
+			sort:     "-taken_at",
+			last:     item{ID: 7, TakenAt: &taken},
+			wantSQL:  `SELECT * FROM "items" WHERE ((("taken_at" < $1 OR "taken_at" IS NULL)) OR ("taken_at" = $2 AND (("id" > $3 OR "id" IS NULL))))`,
+			wantVars: []interface{}{"2026-05-01T12:00:00Z", "2026-05-01T12:00:00Z", int64(7)},
+		},
+		{
+			name:     "NULL is last, only later NULLs follow it",
+			sort:     "-taken_at",
+			last:     item{ID: 7},
+			wantSQL:  `SELECT * FROM "items" WHERE (FALSE OR ("taken_at" IS NULL AND (("id" > $1 OR "id" IS NULL))))`,
+			wantVars: []interface{}{int64(7)},
+		},
+		{
+			name:     "string",
+			sort:     "name",
+			last:     item{ID: 2, Name: "Баня"},
+			wantSQL:  `SELECT * FROM "items" WHERE ((("name" > $1 OR "name" IS NULL)) OR ("name" = $2 AND (("id" > $3 OR "id" IS NULL))))`,
+			wantVars: []interface{}{"Баня", "Баня", int64(2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, key, err := parseSort(tt.sort, spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows := []item{{ID: 1}, tt.last}
+			token, err := nextCursor(db, &rows, len(rows), key, orders)
+			if err != nil || token == "" {
+				t.Fatalf("nextCursor = %q, %v", token, err)
+			}
+
+			condition, err := after(token, key, orders)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt := db.Model(&item{}).Where(condition).Find(&[]item{}).Statement
+			if got := stmt.SQL.String(); got != tt.wantSQL {
+				t.Errorf("SQL:\n got %s\nwant %s", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(stmt.Vars, tt.wantVars) {
+				t.Errorf("vars = %#v, want %#v", stmt.Vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestNextCursorOnlyOnFullPage(t *testing.T) {
+	db := dryRun(t)
+	orders, key, _ := parseSort("", spec)
+	rows := []item{{ID: 1}, {ID: 2}}
+
+	if token, err := nextCursor(db, &rows, 3, key, orders); err != nil || token != "" {
+		t.Errorf("short page: nextCursor = %q, %v; want no cursor", token, err)
+	}
+	empty := []item{}
+	if token, err := nextCursor(db, &empty, 0, key, orders); err != nil || token != "" {
+		t.Errorf("empty page: nextCursor = %q, %v; want no cursor", token, err)
+	}
+}
+
+func TestAfterRejectsBadCursors(t *testing.T) {
+	db := dryRun(t)
+	orders, key, _ := parseSort("position", spec)
+	rows := []item{{ID: 5, Position: 1}}
+	token, err := nextCursor(db, &rows, 1, key, orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherOrders, otherKey, _ := parseSort("-name", spec)
+
+	tests := []struct {
+		name   string
+		token  string
+		key    string
+		orders []order
+	}{
+		{"not base64", "!!!", key, orders},
+		{"not json", "bm90IGpzb24", key, orders},
+		{"wrong number of values", "eyJzIjoicG9zaXRpb24saWQiLCJ2IjpbMV19", key, orders},
+		{"other sort", token, otherKey, otherOrders},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := after(tt.token, tt.key, tt.orders)
+			assertParamError(t, err, "cursor")
+		})
+	}
+}
+
+func TestFindRejectsBadParams(t *testing.T) {
+	db := dryRun(t)
+	noKey := spec
+	noKey.Key = ""
+
+	tests := []struct {
+		name  string
+		query string
+		spec  Spec
+		param string
+	}{
+		{"limit not a number", "limit=ten", spec, "limit"},
+		{"limit zero", "limit=0", spec, "limit"},
+		{"limit over max", "limit=501", spec, "limit"},
+		{"negative offset", "offset=-1", spec, "offset"},
+		{"unknown sort", "sort=email", spec, "sort"},
+		{"bad bool filter", "hidden=maybe", spec, "hidden"},
+		{"bad int filter", "position=first", spec, "position"},
+		{"cursor with offset", "cursor=abc&offset=10", spec, "cursor"},
+		{"cursor without key", "cursor=abc", noKey, "cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			_, err := Find(db, values, tt.spec, &[]item{})
+			assertParamError(t, err, tt.param)
+		})
+	}
+}
+
+func assertParamError(t *testing.T, err error, param string) {
+	t.Helper()
+	var qe *Error
+	if !errors.As(err, &qe) {
+		t.Fatalf("err = %v, want *query.Error", err)
+	}
+	if qe.Param != param {
+		t.Errorf("param = %q, want %q (%s)", qe.Param, param, qe.Message)
+	}
+}
@@ -0,0 +1,203 @@
+package richtext
+
+// Package richtext handles service descriptions written in Markdown with an
+// optional restricted subset of HTML.
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	xhtml "golang.org/x/net/html"
+)
+
+// MaxLength limits the source text, in characters.
+const MaxLength = 50000
+
+var (
+	ErrInvalidUTF8 = errors.New("текст не в кодировке UTF-8")
+	ErrTooLong     = errors.New("текст слишком длинный")
+)
+
+// policy is the HTML allowed on the public site: formatting, lists, links,
+// images and tables, no scripts, styles, iframes or event handlers.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(false)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// Raw HTML in the source is passed through by the renderer and cleaned by
+// policy afterwards.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// Clean validates the Markdown source and sanitizes the HTML embedded in it,
+// leaving the Markdown itself untouched. It is applied before saving, so the
+// stored source never contains markup the site would not render.
+func Clean(source string) (string, error) {
+	if !utf8.ValidString(source) {
+		return "", ErrInvalidUTF8
+	}
+	if utf8.RuneCountInString(source) > MaxLength {
+		return "", ErrTooLong
+	}
+
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+
+	// Inline tags of one block are cleaned one by one but share a balancer,
+	// so the closer of a dropped tag is dropped too.
+	var fragments []fragment
+	balancers := map[ast.Node]*balancer{}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.HTMLBlock:
+			var segs []text.Segment
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segs = append(segs, lines.At(i))
+			}
+			if node.HasClosure() {
+				segs = append(segs, node.ClosureLine)
+			}
+			if len(segs) > 0 {
+				fragments = append(fragments, clean(src, segs, &balancer{}))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			var segs []text.Segment
+			for i := 0; i < node.Segments.Len(); i++ {
+				segs = append(segs, node.Segments.At(i))
+			}
+			block := blockOf(node)
+			if balancers[block] == nil {
+				balancers[block] = &balancer{}
+			}
+			if len(segs) > 0 {
+				fragments = append(fragments, clean(src, segs, balancers[block]))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if len(fragments) == 0 {
+		return source, nil
+	}
+
+	var out bytes.Buffer
+	pos := 0
+	for _, f := range fragments {
+		if f.start < pos {
+			continue
+		}
+		out.Write(src[pos:f.start])
+		out.WriteString(f.text)
+		pos = f.stop
+	}
+	out.Write(src[pos:])
+	return out.String(), nil
+}
+
+// fragment is the cleaned replacement for src[start:stop].
+type fragment struct {
+	start, stop int
+	text        string
+}
+
+// clean sanitizes raw HTML made of segs as a whole, so a tag split across
+// lines stays a tag. Whatever separates the lines in the source, such as the
+// "> " of a blockquote, is put back after every line break of the result, and
+// the trailing line break, which ends a Markdown block, is kept.
+func clean(src []byte, segs []text.Segment, b *balancer) fragment {
+	var raw strings.Builder
+	gap := ""
+	for i, seg := range segs {
+		if i == 1 {
+			gap = string(src[segs[0].Stop:seg.Start])
+		}
+		raw.Write(seg.Value(src))
+	}
+	html := raw.String()
+	trimmed := strings.TrimRight(html, "\r\n")
+	cleaned := b.filter(policy.Sanitize(trimmed))
+	if gap != "" {
+		cleaned = strings.ReplaceAll(cleaned, "\n", "\n"+gap)
+	}
+	return fragment{
+		start: segs[0].Start,
+		stop:  segs[len(segs)-1].Stop,
+		text:  cleaned + html[len(trimmed):],
+	}
+}
+
+// blockOf returns the block, usually a paragraph, that holds an inline node.
+func blockOf(n ast.Node) ast.Node {
+	for n.Parent() != nil && n.Type() != ast.TypeBlock {
+		n = n.Parent()
+	}
+	return n
+}
+
+// voidElements have no closing tag.
+var voidElements = map[string]bool{
+	"area": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "source": true, "track": true, "wbr": true,
+}
+
+// balancer drops closing tags that have no open tag before them, for example
+// the </a> left after policy removed <a href="javascript:...">.
+type balancer struct {
+	open []string
+}
+
+func (b *balancer) filter(s string) string {
+	var out strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			return out.String()
+		}
+		raw := string(z.Raw())
+		name, _ := z.TagName()
+		switch tt {
+		case xhtml.StartTagToken:
+			if !voidElements[string(name)] {
+				b.open = append(b.open, string(name))
+			}
+		case xhtml.EndTagToken:
+			i := len(b.open) - 1
+			for i >= 0 && b.open[i] != string(name) {
+				i--
+			}
+			if i < 0 {
+				continue
+			}
+			b.open = b.open[:i]
+		}
+		out.WriteString(raw)
+	}
+}
+
+// HTML renders the source to HTML that is safe to insert into a page. Older
+// descriptions saved before Clean existed are sanitized here as well.
+func HTML(source string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return ""
+	}
+	return (&balancer{}).filter(policy.Sanitize(buf.String()))
+}
@@ -0,0 +1,144 @@
+package richtext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain markdown",
+			in:   "# Баня\n\n**Жаркая** парная, [бронь](https://example.com).\n\n- веники\n- [x] полотенца\n",
+			want: "# Баня\n\n**Жаркая** парная, [бронь](https://example.com).\n\n- веники\n- [x] полотенца\n",
+		},
+		{
+			name: "javascript link with its closer",
+			in:   "text <a href=\"javascript:alert(1)\">x</a> more",
+			want: "text x more",
+		},
+		{
+			name: "safe link kept",
+			in:   "text <a href=\"https://example.com\">x</a> more",
+			want: "text <a href=\"https://example.com\" target=\"_blank\" rel=\"noopener\">x</a> more",
+		},
+		{
+			name: "event handler",
+			in:   "<p onclick=\"alert(1)\">hi</p>\n",
+			want: "<p>hi</p>\n",
+		},
+		{
+			name: "inline event handler",
+			in:   "an <img src=\"/uploads/a.jpg\" onerror=\"alert(1)\"> image",
+			want: "an <img src=\"/uploads/a.jpg\"> image",
+		},
+		{
+			name: "script block",
+			in:   "<script>\nalert(1)\n</script>\n\nafter\n",
+			want: "\n\nafter\n",
+		},
+		{
+			name: "orphan closer",
+			in:   "text </b> more",
+			want: "text  more",
+		},
+		{
+			name: "orphan closer in a block",
+			in:   "<div>\n</div></span>\n",
+			want: "<div>\n</div>\n",
+		},
+		{
+			name: "tag split across lines",
+			in:   "<div\n  class=\"note\">\nhello\n</div>\n",
+			want: "<div>\nhello\n</div>\n",
+		},
+		{
+			name: "inline tag split across lines",
+			in:   "see <a\nhref=\"https://example.com\">site</a>\n",
+			want: "see <a href=\"https://example.com\" target=\"_blank\" rel=\"noopener\">site</a>\n",
+		},
+		{
+			name: "block in a blockquote",
+			in:   "> <div\n> onclick=\"x\">\n> hi\n> </div>\n",
+			want: "> <div>\n> hi\n> </div>\n",
+		},
+		{
+			name: "markdown around a cleaned block",
+			in:   "Intro *text*\n\n<iframe src=\"https://evil.example\"></iframe>\n\n| a | b |\n|---|---|\n| 1 | 2 |\n",
+			want: "Intro *text*\n\n\n\n| a | b |\n|---|---|\n| 1 | 2 |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Clean(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Clean(%q) =\n%q\nwant\n%q", tt.in, got, tt.want)
+			}
+			again, err := Clean(got)
+			if err != nil || again != got {
+				t.Errorf("Clean is not idempotent: %q -> %q", got, again)
+			}
+		})
+	}
+}
+
+func TestCleanErrors(t *testing.T) {
+	if _, err := Clean("\xff"); err != ErrInvalidUTF8 {
+		t.Errorf("invalid UTF-8: %v", err)
+	}
+	if _, err := Clean(strings.Repeat("я", MaxLength+1)); err != ErrTooLong {
+		t.Errorf("too long: %v", err)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name, in string
+		want     []string
+		reject   []string
+	}{
+		{
+			name: "markdown",
+			in:   "# Title\n\n**bold** and [link](https://example.com)\n",
+			want: []string{"<h1>Title</h1>", "<strong>bold</strong>", `<a href="https://example.com" target="_blank" rel="noopener">link</a>`},
+		},
+		{
+			name:   "javascript url in markdown",
+			in:     "[x](javascript:alert(1))",
+			reject: []string{"javascript:", "</a>"},
+		},
+		{
+			name:   "unsanitized legacy text",
+			in:     "<b onmouseover=\"alert(1)\">hi</b> <script>alert(2)</script></i>",
+			want:   []string{"<b>hi</b>"},
+			reject: []string{"onmouseover", "<script", "alert(2)", "</i>"},
+		},
+		{
+			name: "gfm table and task list",
+			in:   "| a |\n|---|\n| 1 |\n\n- [ ] todo\n",
+			want: []string{"<table>", "<td>1</td>", "<li> todo</li>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HTML(tt.in)
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("HTML = %q, want it to contain %q", got, s)
+				}
+			}
+			for _, s := range tt.reject {
+				if strings.Contains(got, s) {
+					t.Errorf("HTML = %q, must not contain %q", got, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package seed
+
+// Package seed fills an empty database from JSON files: users from
+// data/users.json and optional content fixtures. Content rows are upserted by
+// ID, so running the seed twice leaves the database in the same state. Users
+// are only inserted: an existing account with the same ID or email is never
+// changed.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"admin-api/internal/auth"
+	"admin-api/internal/richtext"
+	"admin-api/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Options struct {
+	UsersFile   string
+	FixturesDir string
+}
+
+// Report counts upserted rows per table; for users, only the inserted ones.
+type Report map[string]int
+
+type seedUser struct {
+	models.Users
+	Password string `json:"password"`
+}
+
+// Run loads all available files inside a single transaction.
+func Run(db *gorm.DB, opts Options) (Report, error) {
+	report := Report{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if opts.UsersFile != "" {
+			n, err := seedUsers(tx, opts.UsersFile)
+			if err != nil {
+				return err
+			}
+			report["users"] = n
+		}
+		if opts.FixturesDir == "" {
+			return nil
+		}
+
+		fixtures := []struct {
+			table string
+			load  func(*gorm.DB, string) (int, error)
+		}{
+			{"services", seedServices},
+			{"gallery", upsertFixture[models.Gallery]},
+			{"docs", upsertFixture[models.Docs]},
+			{"locations", upsertFixture[models.Locations]},
+			{"contacts", replaceContacts},
+		}
+		for _, f := range fixtures {
+			path := filepath.Join(opts.FixturesDir, f.table+".json")
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			n, err := f.load(tx, path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			report[f.table] = n
+		}
+		return nil
+	})
+	return report, err
+}
+
+// seedUsers только добавляет пользователей: если id или email уже заняты,
+// запись из файла пропускается. Иначе повторный seed затёр бы email, роль и
+// пароль настоящей учётной записи — например, администратора, созданного
+// EnsureAdmin с тем же id.
+func seedUsers(tx *gorm.DB, path string) (int, error) {
+	var items []seedUser
+	if err := readJSON(path, &items); err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	created := 0
+	for _, item := range items {
+		user := item.Users
+		user.Email = auth.NormalizeEmail(user.Email)
+		if user.ID <= 0 || user.Email == "" {
+			return 0, fmt.Errorf("%s: у пользователя должны быть id и email", path)
+		}
+		if user.Role == "" {
+			user.Role = models.RoleUser
+		}
+		if user.Status == "" {
+			user.Status = models.StatusActive
+		}
+		if item.Password != "" {
+			hash, err := auth.HashPassword(item.Password)
+			if err != nil {
+				return 0, err
+			}
+			user.PasswordHash = hash
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&user)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected == 0 {
+			log.Printf("users: %d (%s) уже есть, пропущен", user.ID, user.Email)
+			continue
+		}
+		created++
+	}
+	return created, resetSequence(tx, &models.Users{})
+}
+
+func upsertFixture[T any](tx *gorm.DB, path string) (int, error) {
+	var items []T
+	if err := readJSON(path, &items); err != nil {
+		return 0, err
+	}
+	return upsert(tx, items)
+}
+
+// seedServices проводит описания услуг через richtext.Clean, как это делает
+// API при сохранении: фикстура не должна приносить HTML, который не пропустил
+// бы обработчик.
+func seedServices(tx *gorm.DB, path string) (int, error) {
+	var items []models.Services
+	if err := readJSON(path, &items); err != nil {
+		return 0, err
+	}
+	for i := range items {
+		text, err := richtext.Clean(items[i].Text)
+		if err != nil {
+			return 0, fmt.Errorf("услуга %d: %w", items[i].ID, err)
+		}
+		items[i].Text = text
+	}
+	return upsert(tx, items)
+}
+
+func upsert[T any](tx *gorm.DB, items []T) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		UpdateAll: true,
+	}).Create(&items).Error
+	if err != nil {
+		return 0, err
+	}
+	var model T
+	return len(items), resetSequence(tx, &model)
+}
+
+// replaceContacts записывает контакты в основную локацию, создавая её, если
+// локаций ещё нет.
+func replaceContacts(tx *gorm.DB, path string) (int, error) {
+	var contacts models.Contacts
+	if err := readJSON(path, &contacts); err != nil {
+		return 0, err
+	}
+	var location models.Locations
+	err := tx.Where("is_primary").Take(&location).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		location = models.Locations{Name: models.DefaultLocationName, Primary: true, Position: 1}
+	} else if err != nil {
+		return 0, err
+	}
+	location.SetContacts(contacts)
+	return 1, tx.Save(&location).Error
+}
+
+// resetSequence двигает последовательность id после вставки с явными ID,
+// иначе следующий INSERT из API упрётся в уже занятый ключ.
+func resetSequence(tx *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+	return tx.Exec(
+		"SELECT setval(pg_get_serial_sequence(?, 'id'), COALESCE((SELECT MAX(id) FROM "+tx.Statement.Quote(table)+"), 0) + 1, false)",
+		table,
+	).Error
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,131 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"admin-api/internal/auth"
+	"admin-api/internal/dbtest"
+	"admin-api/models"
+)
+
+const usersJSON = `[
+	{"id": 1, "name": "admin", "email": "Admin@Localhost"},
+	{"id": 2, "name": "Jane Smith", "email": "jane@example.com", "role": "Editor", "password": "jane"}
+]`
+
+func writeUsers(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte(usersJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSeedKeepsBootstrappedAdmin(t *testing.T) {
+	db := dbtest.Open(t, &models.Users{})
+
+	created, err := auth.EnsureAdmin(db, "root@example.com", "secret")
+	if err != nil || !created {
+		t.Fatalf("EnsureAdmin = %v, %v", created, err)
+	}
+	var admin models.Users
+	if err := db.Where("email = ?", "root@example.com").Take(&admin).Error; err != nil {
+		t.Fatal(err)
+	}
+	if admin.ID != 1 {
+		t.Fatalf("admin id = %d, want 1 to collide with the fixture", admin.ID)
+	}
+
+	path := writeUsers(t)
+	for i := 0; i < 2; i++ {
+		report, err := Run(db, Options{UsersFile: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 1
+		if i > 0 {
+			want = 0
+		}
+		if report["users"] != want {
+			t.Errorf("run %d: users = %d, want %d", i+1, report["users"], want)
+		}
+	}
+
+	var got models.Users
+	if err := db.Take(&got, admin.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != admin.Email || got.Role != models.RoleAdmin || got.PasswordHash != admin.PasswordHash {
+		t.Errorf("admin after seed = %+v, want unchanged %+v", got, admin)
+	}
+	if err := auth.CheckPassword(got.PasswordHash, "secret"); err != nil {
+		t.Error("admin can't log in after seed")
+	}
+
+	var jane models.Users
+	if err := db.Take(&jane, 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	if jane.Role != models.RoleEditor || auth.CheckPassword(jane.PasswordHash, "jane") != nil {
+		t.Errorf("jane = %+v, want an Editor with the fixture password", jane)
+	}
+
+	// The next user created through the API mustn't hit a taken id.
+	next := models.Users{Name: "new", Email: "new@example.com", Role: models.RoleUser, Status: models.StatusActive}
+	if err := db.Create(&next).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnsureAdminClaimsSeededUser(t *testing.T) {
+	db := dbtest.Open(t, &models.Users{})
+
+	if _, err := Run(db, Options{UsersFile: writeUsers(t)}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := auth.EnsureAdmin(db, "admin@localhost", "secret")
+	if err != nil || !created {
+		t.Fatalf("EnsureAdmin = %v, %v", created, err)
+	}
+
+	var admin models.Users
+	if err := db.Take(&admin, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if admin.Email != "admin@localhost" || admin.Role != models.RoleAdmin || admin.Status != models.StatusActive {
+		t.Errorf("admin = %+v, want an active Admin", admin)
+	}
+	if err := auth.CheckPassword(admin.PasswordHash, "secret"); err != nil {
+		t.Error("admin can't log in")
+	}
+
+	created, err = auth.EnsureAdmin(db, "admin@localhost", "other")
+	if err != nil || created {
+		t.Errorf("second EnsureAdmin = %v, %v; want no change", created, err)
+	}
+}
+
+func TestSeedCleansServiceText(t *testing.T) {
+	db := dbtest.Open(t, &models.Services{}, &models.ServicePrices{}, &models.Gallery{}, &models.GalleryVariant{},
+		&models.ServiceImages{})
+
+	dir := t.TempDir()
+	fixture := `[{"id": 1, "eng": "banya", "title": "Баня", "text": "**Парная** <b onclick=\"x\">жар</b>, <a href=\"javascript:alert(1)\">бронь</a>"}]`
+	if err := os.WriteFile(filepath.Join(dir, "services.json"), []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(db, Options{FixturesDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	var service models.Services
+	if err := db.Take(&service, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := "**Парная** <b>жар</b>, бронь"; service.Text != want {
+		t.Errorf("text = %q, want %q", service.Text, want)
+	}
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local keeps files in a directory on disk and serves them under BaseURL.
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocal(dir, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("не удалось создать папку %s: %w", dir, err)
+	}
+	return &Local{Dir: dir, BaseURL: baseURL}, nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if !validKey(key) {
+		return fmt.Errorf("storage: недопустимый ключ %q", key)
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы клиенты никогда
+	// не увидели недописанный файл.
+	tmp, err := os.CreateTemp(l.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(l.Dir, key))
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(filepath.Join(l.Dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return nil
+	}
+	err := os.Remove(filepath.Join(l.Dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Local) List(ctx context.Context) ([]Object, error) {
+	entries, err := os.ReadDir(l.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var objects []Object
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{Key: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return objects, nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.BaseURL + key
+}
+
+// Handler serves the directory with http.FileServer, which unlike the
+// generic Handler supports Range and If-Modified-Since.
+func (l *Local) Handler() http.Handler {
+	return http.FileServer(http.Dir(l.Dir))
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newLocal(t *testing.T) (*Local, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "uploads")
+	l, err := NewLocal(dir, "/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, dir
+}
+
+func put(t *testing.T, l *Local, key, content string) {
+	t.Helper()
+	if err := l.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	l, dir := newLocal(t)
+	ctx := context.Background()
+
+	put(t, l, "a.txt", "first")
+	put(t, l, "a.txt", "second")
+	put(t, l, "b.jpg", "image")
+
+	r, err := l.Get(ctx, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "second" {
+		t.Errorf("Get = %q, want the replaced content", data)
+	}
+	if _, err := l.Get(ctx, "missing.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing = %v, want ErrNotFound", err)
+	}
+
+	// Leftovers of an interrupted Put and directories are not objects.
+	os.WriteFile(filepath.Join(dir, ".tmp-123"), []byte("partial"), 0o644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	objects, err := l.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, obj := range objects {
+		keys = append(keys, obj.Key)
+		if obj.Key == "b.jpg" && obj.Size != 5 {
+			t.Errorf("size of b.jpg = %d, want 5", obj.Size)
+		}
+	}
+	sort.Strings(keys)
+	if strings.Join(keys, ",") != "a.txt,b.jpg" {
+		t.Errorf("List = %v, want [a.txt b.jpg]", keys)
+	}
+
+	if err := l.Delete(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Delete(ctx, "a.txt"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+	if _, err := l.Get(ctx, "a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	l, dir := newLocal(t)
+	ctx := context.Background()
+	secret := filepath.Join(filepath.Dir(dir), "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", ".", "..", "../secret.txt", "/etc/passwd", secret, `..\secret.txt`, `C:\secret.txt`, "sub/a.txt"} {
+		if validKey(key) {
+			t.Errorf("validKey(%q) = true", key)
+		}
+		if err := l.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if _, err := l.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want ErrNotFound", key, err)
+		}
+		if err := l.Delete(ctx, key); err != nil {
+			t.Errorf("Delete(%q) = %v", key, err)
+		}
+	}
+	if _, err := os.Stat(secret); err != nil {
+		t.Error("a file outside the storage was touched")
+	}
+	for _, key := range []string{"a.txt", "upload_1700000000_abc.jpg", "..hidden"} {
+		if !validKey(key) {
+			t.Errorf("validKey(%q) = false", key)
+		}
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	l, _ := newLocal(t)
+	tests := []struct {
+		url  string
+		key  string
+		isOK bool
+	}{
+		{"/uploads/a.jpg", "a.jpg", true},
+		{l.URL("b.pdf"), "b.pdf", true},
+		{"/uploads/", "", false},
+		{"/uploads/../main.go", "", false},
+		{"/uploads/sub/a.jpg", "", false},
+		{"https://example.com/uploads/a.jpg", "", false},
+		{"/static/a.jpg", "", false},
+	}
+	for _, tt := range tests {
+		key, ok := KeyOf(l, tt.url)
+		if ok != tt.isOK || (ok && key != tt.key) {
+			t.Errorf("KeyOf(%q) = %q, %v; want %q, %v", tt.url, key, ok, tt.key, tt.isOK)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	l, _ := newLocal(t)
+	put(t, l, "photo.png", "png data")
+
+	srv := httptest.NewServer(http.StripPrefix("/uploads/", Handler(l)))
+	defer srv.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		ctype  string
+	}{
+		{"/uploads/photo.png", http.StatusOK, "png data", "image/png"},
+		{"/uploads/missing.png", http.StatusNotFound, "", ""},
+		{"/uploads/", http.StatusNotFound, "", ""},
+		{"/uploads/sub/photo.png", http.StatusNotFound, "", ""},
+		{"/uploads/..%5Csecret.txt", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.status)
+			continue
+		}
+		if tt.status == http.StatusOK {
+			if string(body) != tt.body || resp.Header.Get("Content-Type") != tt.ctype {
+				t.Errorf("GET %s = %q (%s), want %q (%s)", tt.path, body, resp.Header.Get("Content-Type"), tt.body, tt.ctype)
+			}
+		}
+	}
+}
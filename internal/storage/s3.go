@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// PublicURL is the base URL objects are downloaded from, e.g. a CDN or
+	// "http://localhost:9000/admin-uploads/". When empty, files are proxied
+	// through the API under fallbackURL.
+	PublicURL string
+}
+
+// S3 stores files in an S3-compatible bucket (AWS S3, MinIO, Yandex Object Storage).
+type S3 struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewS3 connects to the bucket and creates it if it does not exist yet,
+// which is convenient for a local MinIO.
+func NewS3(ctx context.Context, cfg S3Config, fallbackURL string) (*S3, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("s3: не удалось проверить бакет %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("s3: не удалось создать бакет %s: %w", cfg.Bucket, err)
+		}
+	}
+
+	baseURL := cfg.PublicURL
+	if baseURL == "" {
+		baseURL = fallbackURL
+	}
+	return &S3{client: client, bucket: cfg.Bucket, baseURL: baseURL}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if !validKey(key) {
+		return fmt.Errorf("storage: недопустимый ключ %q", key)
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrNotFound
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject ленивый: отсутствие объекта выясняется только при Stat.
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return nil
+	}
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *S3) List(ctx context.Context) ([]Object, error) {
+	var objects []Object
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, Object{Key: info.Key, Size: info.Size, ModTime: info.LastModified})
+	}
+	return objects, nil
+}
+
+func (s *S3) URL(key string) string {
+	return s.baseURL + key
+}
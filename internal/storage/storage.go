@@ -0,0 +1,81 @@
+package storage
+
+// Package storage hides where uploaded files live. Handlers work with keys
+// (flat file names such as "upload_1700000000_abcdef.jpg") and ask the
+// storage for a public URL; the backend is chosen by configuration.
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+type Storage interface {
+	// Put stores r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object for reading. Returns ErrNotFound if it is missing.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the address clients use to download the object.
+	URL(key string) string
+	// List returns all stored objects.
+	List(ctx context.Context) ([]Object, error)
+}
+
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// KeyOf returns the key of a file by the URL that s.URL produced for it.
+// URLs pointing elsewhere (external links, other backends) return false.
+func KeyOf(s Storage, url string) (string, bool) {
+	base := s.URL("")
+	if !strings.HasPrefix(url, base) {
+		return "", false
+	}
+	key := url[len(base):]
+	return key, validKey(key)
+}
+
+// Handler serves objects by key from any Storage, e.g. mounted under
+// /uploads/ when files live in S3 but old /uploads/... links must keep working.
+func Handler(s Storage) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if key == "" || strings.Contains(key, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		obj, err := s.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Ошибка хранилища", http.StatusBadGateway)
+			return
+		}
+		defer obj.Close()
+
+		if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		io.Copy(w, obj)
+	})
+}
+
+// validKey rejects keys that could escape the storage root.
+func validKey(key string) bool {
+	return key != "" && key != "." && key != ".." && !strings.ContainsAny(key, `/\`)
+}
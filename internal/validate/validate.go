@@ -0,0 +1,184 @@
+package validate
+
+// Package validate checks and normalizes contact data: phone numbers, emails
+// and links. Problems are collected per field so that the client can show
+// each message next to its input.
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Domains of the social links in contacts and locations.
+var (
+	VKDomains     = []string{"vk.com", "vk.ru"}
+	YandexDomains = []string{"yandex.ru", "yandex.com", "ya.ru"}
+	TwoGisDomains = []string{"2gis.ru", "2gis.com"}
+)
+
+var (
+	ErrPhone  = errors.New("телефон должен быть в международном формате, например +7 900 123-45-67")
+	ErrEmail  = errors.New("неверный email")
+	ErrURL    = errors.New("неверная ссылка, нужен адрес вида https://example.com")
+	ErrDomain = errors.New("ссылка ведёт не на тот сайт")
+)
+
+// Errors maps JSON field names to messages. Only the first message per field
+// is kept.
+type Errors map[string]string
+
+func (e Errors) Add(field, message string) {
+	if _, ok := e[field]; !ok {
+		e[field] = message
+	}
+}
+
+// Err returns the collected errors, or nil when there are none.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + ": " + e[field]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Required records an error when value is blank.
+func (e Errors) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, "обязательное поле")
+	}
+}
+
+// Phone normalizes *value to E.164 in place; an empty value is left alone.
+func (e Errors) Phone(field string, value *string) {
+	if *value == "" {
+		return
+	}
+	phone, err := Phone(*value)
+	if err != nil {
+		e.Add(field, err.Error())
+		return
+	}
+	*value = phone
+}
+
+// Email trims *value and checks its syntax; an empty value is left alone.
+func (e Errors) Email(field string, value *string) {
+	if *value == "" {
+		return
+	}
+	email, err := Email(*value)
+	if err != nil {
+		e.Add(field, err.Error())
+		return
+	}
+	*value = email
+}
+
+// URL normalizes *value to an absolute http(s) link on one of domains, or on
+// any domain when none are given; an empty value is left alone.
+func (e Errors) URL(field string, value *string, domains ...string) {
+	if *value == "" {
+		return
+	}
+	link, err := URL(*value, domains...)
+	if err != nil {
+		e.Add(field, err.Error())
+		return
+	}
+	*value = link
+}
+
+// Phone returns the number in E.164 form, "+79001234567". Russian numbers
+// may be written with a leading 8 or as ten digits without the country code,
+// mobile or landline: "4951234567" becomes "+74951234567".
+func Phone(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	plus := strings.HasPrefix(s, "+")
+
+	var digits strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0, r == ' ', r == '-', r == '(', r == ')', r == '.', r == '\u00a0':
+		default:
+			return "", ErrPhone
+		}
+	}
+	d := digits.String()
+
+	switch {
+	case plus:
+	case len(d) == 11 && d[0] == '8':
+		d = "7" + d[1:]
+	case len(d) == 11 && d[0] == '7':
+	case len(d) == 10 && strings.IndexByte("34789", d[0]) >= 0:
+		// No country code: area codes under +7 start with 3, 4, 7, 8 or 9.
+		d = "7" + d
+	default:
+		return "", ErrPhone
+	}
+	if len(d) < 8 || len(d) > 15 || d[0] == '0' {
+		return "", ErrPhone
+	}
+	return "+" + d, nil
+}
+
+// Email returns the trimmed address if it is a bare address with a dotted
+// domain. Case is preserved; user accounts lower-case it with
+// auth.NormalizeEmail.
+func Email(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return "", ErrEmail
+	}
+	at := strings.LastIndex(s, "@")
+	domain := s[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", ErrEmail
+	}
+	return s, nil
+}
+
+// URL returns an absolute http(s) link. A link without a scheme gets
+// https://. With domains, the host must be one of them or their subdomain.
+func URL(s string, domains ...string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return "", ErrURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if !strings.Contains(host, ".") {
+		return "", ErrURL
+	}
+	if len(domains) == 0 {
+		return u.String(), nil
+	}
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return u.String(), nil
+		}
+	}
+	return "", errors.New(ErrDomain.Error() + ", ожидается " + strings.Join(domains, " или "))
+}
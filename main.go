@@ -15,16 +15,23 @@
 // @securityDefinitions.apikey  ApiKeyAuth
 // @in                          header
 // @name                        Authorization
-// @description                 Опционально: можно добавить JWT в будущем
+// @description                 Токен сессии из POST /auth/login в формате "Bearer <token>"
 
 package main
 
 import (
 	"admin-api/handlers"
+	"admin-api/internal/auth"
+	"admin-api/internal/config"
 	"admin-api/internal/db"
+	"admin-api/internal/storage"
 	"admin-api/models"
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -35,47 +42,179 @@ import (
 )
 
 func main() {
-	dsn := "host=localhost user=admin password=adminpass dbname=admin_api port=5432 sslmode=disable"
-	dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		log.Fatal("Ошибка конфигурации:\n", err)
+	}
+
+	dbConn, err := gorm.Open(postgres.Open(cfg.DB.DSN()), &gorm.Config{
+		// Нарушение уникального индекса приходит как gorm.ErrDuplicatedKey,
+		// по нему обработчики отвечают 409.
+		TranslateError: true,
+	})
 	if err != nil {
 		log.Fatal("Не удалось подключиться к БД:", err)
 	}
 	db.Set(dbConn)
 
-	err = dbConn.AutoMigrate(&models.Services{}, &models.Gallery{}, &models.Docs{}, &models.Contacts{})
+	if err := migrateContacts(dbConn); err != nil {
+		log.Fatal("Ошибка миграции контактов:", err)
+	}
+	if err := migrateServicesEng(dbConn); err != nil {
+		log.Fatal("Ошибка подготовки eng услуг к уникальному индексу:", err)
+	}
+	err = dbConn.AutoMigrate(&models.Services{}, &models.ServicePrices{}, &models.PriceRules{}, &models.Gallery{}, &models.GalleryVariant{}, &models.ServiceImages{}, &models.Docs{}, &models.Locations{}, &models.Users{}, &models.Sessions{}, &models.Files{}, &models.Albums{}, &models.AlbumPhotos{})
 	if err != nil {
 		log.Fatal("Ошибка миграции:", err)
 	}
+	if err := migrateLocations(dbConn); err != nil {
+		log.Fatal("Ошибка переноса контактов в локации:", err)
+	}
+	if err := migrateUserEmails(dbConn); err != nil {
+		log.Fatal("Ошибка приведения email пользователей к нижнему регистру:", err)
+	}
 
+	store, err := newStorage(cfg.Uploads)
+	if err != nil {
+		log.Fatal("Не удалось подключить хранилище файлов:", err)
+	}
+
+	uploads := handlers.Uploads{
+		Storage: store,
+		MaxSize: int64(cfg.Uploads.MaxSize),
+		Limits: handlers.Limits{
+			Gallery: int64(cfg.Uploads.Limits.Gallery),
+			Docs:    int64(cfg.Uploads.Limits.Docs),
+			Avatar:  int64(cfg.Uploads.Limits.Avatar),
+		},
+		MaxPixels: cfg.Uploads.MaxPixels,
+	}
+
+	if len(os.Args) > 1 {
+		runCommand(dbConn, uploads, os.Args[1], os.Args[2:])
+		return
+	}
+
+	created, err := auth.EnsureAdmin(dbConn, cfg.Auth.AdminEmail, cfg.Auth.AdminPassword)
+	if err != nil {
+		log.Fatal("Не удалось создать администратора:", err)
+	}
+	if created {
+		log.Println("Создан администратор", cfg.Auth.AdminEmail)
+	}
+
+	handlers.SetAllowedOrigins(cfg.CORS.Origins)
+	authAPI := handlers.NewAuthAPI(dbConn, time.Duration(cfg.Auth.SessionTTL))
 	servicesAPI := handlers.NewServicesAPI(dbConn)
-	galleryAPI := handlers.NewGalleryAPI(dbConn)
+	pricesAPI := handlers.NewPricesAPI(dbConn)
+	galleryAPI := handlers.NewGalleryAPI(dbConn, uploads)
+	albumsAPI := handlers.NewAlbumsAPI(dbConn)
 	contactsAPI := handlers.NewContactsAPI(dbConn)
-	docsAPI := handlers.NewDocsAPI(dbConn)
+	locationsAPI := handlers.NewLocationsAPI(dbConn)
+	docsAPI := handlers.NewDocsAPI(dbConn, uploads)
+	usersAPI := handlers.NewUsersAPI(dbConn, uploads)
+
+	if local, ok := store.(*storage.Local); ok {
+		http.Handle("/uploads/", http.StripPrefix("/uploads/", local.Handler()))
+	} else {
+		http.Handle("/uploads/", http.StripPrefix("/uploads/", storage.Handler(store)))
+	}
 
-	http.Handle("/uploads/", http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	http.HandleFunc("POST /auth/login", handlers.WithCORS(authAPI.Login))
+	http.HandleFunc("POST /auth/logout", handlers.WithCORS(authAPI.Logout))
+	http.HandleFunc("GET /auth/me", handlers.WithCORS(authAPI.RequireAuth(authAPI.Me)))
 
 	http.HandleFunc("GET /services", handlers.WithCORS(servicesAPI.GetServices))
-	http.HandleFunc("POST /services", handlers.WithCORS(servicesAPI.CreateService))
-	http.HandleFunc("PUT /services/{id}", handlers.WithCORS(servicesAPI.UpdateService))
+	http.HandleFunc("POST /services", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, servicesAPI.CreateService)))
+	http.HandleFunc("GET /services/{id}", handlers.WithCORS(servicesAPI.GetService))
+	http.HandleFunc("GET /services/by-slug/{eng}", handlers.WithCORS(servicesAPI.GetServiceBySlug))
+	http.HandleFunc("PUT /services/{id}", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, servicesAPI.UpdateService)))
+	http.HandleFunc("DELETE /services/{id}", handlers.WithCORS(authAPI.Require(auth.PermServicesDelete, servicesAPI.DeleteService)))
+	http.HandleFunc("PUT /services/{id}/images", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, servicesAPI.SetServiceImages)))
+	http.HandleFunc("GET /services/{id}/prices", handlers.WithCORS(pricesAPI.GetPrices))
+	http.HandleFunc("POST /services/{id}/prices", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, pricesAPI.CreatePrice)))
+	http.HandleFunc("PUT /services/{id}/prices/{priceId}", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, pricesAPI.UpdatePrice)))
+	http.HandleFunc("DELETE /services/{id}/prices/{priceId}", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, pricesAPI.DeletePrice)))
+	http.HandleFunc("GET /services/{id}/price", handlers.WithCORS(pricesAPI.GetEffectivePrice))
+	http.HandleFunc("GET /services/{id}/price-rules", handlers.WithCORS(pricesAPI.GetPriceRules))
+	http.HandleFunc("POST /services/{id}/price-rules", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, pricesAPI.CreatePriceRule)))
+	http.HandleFunc("PUT /services/{id}/price-rules/{ruleId}", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, pricesAPI.UpdatePriceRule)))
+	http.HandleFunc("DELETE /services/{id}/price-rules/{ruleId}", handlers.WithCORS(authAPI.Require(auth.PermServicesWrite, pricesAPI.DeletePriceRule)))
 
 	http.HandleFunc("GET /gallery", handlers.WithCORS(galleryAPI.GetGallery))
-	http.HandleFunc("POST /gallery", handlers.WithCORS(galleryAPI.UploadGalleryFiles))
-	http.HandleFunc("PUT /gallery/{id}", handlers.WithCORS(galleryAPI.UpdateGallery))
-	http.HandleFunc("DELETE /gallery/{id}", handlers.WithCORS(galleryAPI.DeleteGallery))
+	http.HandleFunc("POST /gallery", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, galleryAPI.UploadGalleryFiles)))
+	http.HandleFunc("PATCH /gallery/order", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, galleryAPI.ReorderGallery)))
+	http.HandleFunc("PUT /gallery/{id}", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, galleryAPI.UpdateGallery)))
+	http.HandleFunc("DELETE /gallery/{id}", handlers.WithCORS(authAPI.Require(auth.PermGalleryDelete, galleryAPI.DeleteGallery)))
+
+	http.HandleFunc("GET /albums", handlers.WithCORS(albumsAPI.GetAlbums))
+	http.HandleFunc("GET /albums/{id}", handlers.WithCORS(albumsAPI.GetAlbum))
+	http.HandleFunc("POST /albums", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, albumsAPI.CreateAlbum)))
+	http.HandleFunc("PUT /albums/{id}", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, albumsAPI.UpdateAlbum)))
+	http.HandleFunc("DELETE /albums/{id}", handlers.WithCORS(authAPI.Require(auth.PermGalleryDelete, albumsAPI.DeleteAlbum)))
+	http.HandleFunc("POST /albums/{id}/photos", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, albumsAPI.AddAlbumPhotos)))
+	http.HandleFunc("DELETE /albums/{id}/photos/{photoId}", handlers.WithCORS(authAPI.Require(auth.PermGalleryWrite, albumsAPI.RemoveAlbumPhoto)))
 
 	http.HandleFunc("GET /contacts", handlers.WithCORS(contactsAPI.GetContacts))
-	http.HandleFunc("PUT /contacts", handlers.WithCORS(contactsAPI.UpdateContacts))
+	http.HandleFunc("GET /contacts/open-now", handlers.WithCORS(contactsAPI.GetOpenNow))
+	http.HandleFunc("GET /contacts.jsonld", handlers.WithCORS(contactsAPI.GetContactsJSONLD))
+	http.HandleFunc("GET /contacts.vcf", handlers.WithCORS(contactsAPI.GetContactsVCard))
+	http.HandleFunc("PUT /contacts", handlers.WithCORS(authAPI.Require(auth.PermContactsWrite, contactsAPI.UpdateContacts)))
+	http.HandleFunc("PATCH /contacts", handlers.WithCORS(authAPI.Require(auth.PermContactsWrite, contactsAPI.PatchContacts)))
+
+	http.HandleFunc("GET /locations", handlers.WithCORS(locationsAPI.GetLocations))
+	http.HandleFunc("GET /locations/{id}", handlers.WithCORS(locationsAPI.GetLocation))
+	http.HandleFunc("GET /locations/{id}/open-now", handlers.WithCORS(locationsAPI.GetLocationOpenNow))
+	http.HandleFunc("POST /locations", handlers.WithCORS(authAPI.Require(auth.PermContactsWrite, locationsAPI.CreateLocation)))
+	http.HandleFunc("PUT /locations/{id}", handlers.WithCORS(authAPI.Require(auth.PermContactsWrite, locationsAPI.UpdateLocation)))
+	http.HandleFunc("DELETE /locations/{id}", handlers.WithCORS(authAPI.Require(auth.PermContactsWrite, locationsAPI.DeleteLocation)))
 
 	http.HandleFunc("GET /docs", handlers.WithCORS(docsAPI.GetDocs))
-	http.HandleFunc("POST /docs", handlers.WithCORS(docsAPI.UploadDocsFiles))
-	http.HandleFunc("PUT /docs/{id}", handlers.WithCORS(docsAPI.UpdateDocs))
-	http.HandleFunc("DELETE /docs/{id}", handlers.WithCORS(docsAPI.DeleteDocs))
+	http.HandleFunc("POST /docs", handlers.WithCORS(authAPI.Require(auth.PermDocsWrite, docsAPI.UploadDocsFiles)))
+	http.HandleFunc("PUT /docs/{id}", handlers.WithCORS(authAPI.Require(auth.PermDocsWrite, docsAPI.UpdateDocs)))
+	http.HandleFunc("DELETE /docs/{id}", handlers.WithCORS(authAPI.Require(auth.PermDocsDelete, docsAPI.DeleteDocs)))
+
+	http.HandleFunc("GET /users", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.GetUsers)))
+	http.HandleFunc("POST /users", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.CreateUser)))
+	http.HandleFunc("GET /users/{id}", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.GetUser)))
+	http.HandleFunc("PUT /users/{id}", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.UpdateUser)))
+	http.HandleFunc("DELETE /users/{id}", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.DeleteUser)))
+	http.HandleFunc("PUT /users/{id}/status", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.UpdateUserStatus)))
+	http.HandleFunc("PUT /users/{id}/role", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.UpdateUserRole)))
+	http.HandleFunc("POST /users/{id}/avatar", handlers.WithCORS(authAPI.Require(auth.PermUsersManage, usersAPI.UploadUserAvatar)))
 
 	// Swagger UI
 	http.HandleFunc("/swagger/", httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:8080/swagger/doc.json"),
+		httpSwagger.URL(strings.TrimRight(cfg.HTTP.PublicURL, "/")+"/swagger/doc.json"),
 	))
 
-	log.Println("Server started at :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Println("Server started at " + cfg.HTTP.Addr)
+	log.Fatal(http.ListenAndServe(cfg.HTTP.Addr, nil))
+}
+
+// newStorage выбирает хранилище загрузок по uploads.backend.
+func newStorage(cfg config.UploadsConfig) (storage.Storage, error) {
+	if cfg.Backend == "s3" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return storage.NewS3(ctx, storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+			PublicURL: cfg.S3.PublicURL,
+		}, "/uploads/")
+	}
+	return storage.NewLocal(cfg.Dir, "/uploads/")
+}
+
+// configPath возвращает путь к YAML-конфигу: CONFIG_FILE или config.yaml
+// в текущей папке. Файл из CONFIG_FILE обязан существовать.
+func configPath() (string, bool) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path, true
+	}
+	return "config.yaml", false
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"admin-api/internal/auth"
+	"admin-api/models"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// migrateContacts готовит старую таблицу contacts без первичного ключа к
+// AutoMigrate: все строки сливаются в одну с id = 1. Из нескольких строк
+// для каждого поля берётся первое непустое значение.
+func migrateContacts(dbConn *gorm.DB) error {
+	m := dbConn.Migrator()
+	if !m.HasTable(&models.Contacts{}) || m.HasColumn(&models.Contacts{}, "ID") {
+		return nil
+	}
+
+	return dbConn.Transaction(func(tx *gorm.DB) error {
+		var rows []models.Contacts
+		if err := tx.Find(&rows).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM contacts").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("ALTER TABLE contacts ADD COLUMN id bigint NOT NULL DEFAULT 1 PRIMARY KEY").Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		merged := rows[0]
+		for _, row := range rows[1:] {
+			fillEmpty(&merged, row)
+		}
+		merged.ID = models.ContactsID
+		if len(rows) > 1 {
+			log.Printf("contacts: %d записей объединены в одну", len(rows))
+		}
+		return tx.Create(&merged).Error
+	})
+}
+
+// migrateServicesEng готовит services к уникальному индексу на eng, пока его
+// ещё нет: иначе AutoMigrate упадёт на повторах и сервер не запустится. Из
+// услуг с одинаковым eng своё значение сохраняет самая ранняя, остальные
+// получают суффикс -<id>; пустой eng заменяется на service-<id>. Каждое
+// переименование пишется в лог.
+func migrateServicesEng(dbConn *gorm.DB) error {
+	m := dbConn.Migrator()
+	if !m.HasTable(&models.Services{}) || m.HasIndex(&models.Services{}, "Eng") {
+		return nil
+	}
+
+	return dbConn.Transaction(func(tx *gorm.DB) error {
+		var rows []models.Services
+		if err := tx.Select("id", "eng").Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		taken := map[string]bool{}
+		for _, row := range rows {
+			taken[row.Eng] = true
+		}
+
+		kept := map[string]bool{}
+		for _, row := range rows {
+			if row.Eng != "" && !kept[row.Eng] {
+				kept[row.Eng] = true
+				continue
+			}
+			base := row.Eng
+			if base == "" {
+				base = "service"
+			}
+			renamed := fmt.Sprintf("%s-%d", base, row.ID)
+			for taken[renamed] {
+				renamed += fmt.Sprintf("-%d", row.ID)
+			}
+			taken[renamed] = true
+			if err := tx.Model(&models.Services{}).Where("id = ?", row.ID).Update("eng", renamed).Error; err != nil {
+				return err
+			}
+			log.Printf("services: услуга %d: eng %q заменён на %q", row.ID, row.Eng, renamed)
+		}
+		return nil
+	})
+}
+
+// migrateUserEmails приводит email пользователей к нижнему регистру: вход
+// ищет пользователя по email в таком виде. Если два пользователя отличаются
+// только регистром email, оба остаются как есть, а в лог пишется
+// предупреждение — кого оставить, решает администратор.
+func migrateUserEmails(dbConn *gorm.DB) error {
+	if !dbConn.Migrator().HasTable(&models.Users{}) {
+		return nil
+	}
+
+	return dbConn.Transaction(func(tx *gorm.DB) error {
+		var rows []models.Users
+		if err := tx.Select("id", "email").Where("email <> lower(trim(email))").Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			email := auth.NormalizeEmail(row.Email)
+			var count int64
+			if err := tx.Model(&models.Users{}).Where("lower(trim(email)) = ? AND id <> ?", email, row.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				log.Printf("users: email %q пользователя %d совпадает с другим без учёта регистра, оставлен как есть", row.Email, row.ID)
+				continue
+			}
+			if err := tx.Model(&models.Users{}).Where("id = ?", row.ID).Update("email", email).Error; err != nil {
+				return err
+			}
+			log.Printf("users: пользователь %d: email %q заменён на %q", row.ID, row.Email, email)
+		}
+		return nil
+	})
+}
+
+// fillEmpty копирует в пустые строковые поля dst значения из src.
+func fillEmpty(dst *models.Contacts, src models.Contacts) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src)
+	for i := 0; i < d.NumField(); i++ {
+		if f := d.Field(i); f.Kind() == reflect.String && f.String() == "" {
+			f.SetString(s.Field(i).String())
+		}
+	}
+}
+
+// legacyContactsTable — имя, под которым остаётся прежняя таблица contacts
+// после переноса в локации.
+const legacyContactsTable = "contacts_legacy"
+
+// migrateLocations переносит контакты из прежней таблицы contacts в основную
+// локацию, пока локаций ещё нет, и переименовывает таблицу в contacts_legacy.
+// Перенос выполняется один раз: иначе после удаления всех локаций старые
+// контакты вернулись бы при следующем запуске.
+func migrateLocations(dbConn *gorm.DB) error {
+	if !dbConn.Migrator().HasTable(&models.Contacts{}) {
+		return nil
+	}
+
+	return dbConn.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Locations{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			var contacts models.Contacts
+			err := tx.Take(&contacts, models.ContactsID).Error
+			if err == nil {
+				location := models.Locations{Name: models.DefaultLocationName, Primary: true, Position: 1}
+				location.SetContacts(contacts)
+				if err := tx.Create(&location).Error; err != nil {
+					return err
+				}
+				log.Println("contacts: данные перенесены в основную локацию")
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		m := tx.Migrator()
+		if m.HasTable(legacyContactsTable) {
+			log.Printf("contacts: таблица %s уже есть, contacts удалена", legacyContactsTable)
+			return m.DropTable(&models.Contacts{})
+		}
+		log.Printf("contacts: таблица переименована в %s", legacyContactsTable)
+		return m.RenameTable(&models.Contacts{}, legacyContactsTable)
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"admin-api/handlers"
+	"admin-api/internal/dbtest"
+	"admin-api/models"
+)
+
+func TestMigrateLocationsRunsOnce(t *testing.T) {
+	db := dbtest.Open(t, &models.Contacts{}, &models.Locations{})
+	legacy := models.Contacts{ID: models.ContactsID, Address: "ул. Старая, 1", Phone: "+79001234567", Email: "old@example.com"}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateLocations(db); err != nil {
+		t.Fatal(err)
+	}
+	var location models.Locations
+	if err := db.Take(&location).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !location.Primary || location.Address != legacy.Address {
+		t.Fatalf("location = %+v, want the primary one with the legacy address", location)
+	}
+	if db.Migrator().HasTable(&models.Contacts{}) || !db.Migrator().HasTable(legacyContactsTable) {
+		t.Fatal("contacts must be renamed to " + legacyContactsTable)
+	}
+
+	// The last location may be deleted; the next start must not bring the
+	// legacy contacts back.
+	req := httptest.NewRequest(http.MethodDelete, "/locations/"+strconv.Itoa(location.ID), nil)
+	req.SetPathValue("id", strconv.Itoa(location.ID))
+	rec := httptest.NewRecorder()
+	handlers.NewLocationsAPI(db).DeleteLocation(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DeleteLocation = %d %s", rec.Code, rec.Body)
+	}
+
+	if err := migrateLocations(db); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.Model(&models.Locations{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("locations after restart = %d, want 0", count)
+	}
+}
+
+func TestMigrateLocationsKeepsExisting(t *testing.T) {
+	db := dbtest.Open(t, &models.Contacts{}, &models.Locations{})
+	if err := db.Create(&models.Contacts{ID: models.ContactsID, Address: "ул. Старая, 1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	current := models.Locations{Name: "Главная", Address: "ул. Новая, 2", Primary: true, Position: 1}
+	if err := db.Create(&current).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateLocations(db); err != nil {
+		t.Fatal(err)
+	}
+	var locations []models.Locations
+	if err := db.Find(&locations).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 1 || locations[0].Address != current.Address {
+		t.Errorf("locations = %+v, want only the existing one", locations)
+	}
+	if db.Migrator().HasTable(&models.Contacts{}) {
+		t.Error("contacts must be renamed even when locations already exist")
+	}
+}
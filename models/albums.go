@@ -0,0 +1,32 @@
+package models
+
+const (
+	VisibilityPublic = "public"
+	VisibilityHidden = "hidden"
+)
+
+// Albums — раздел галереи на сайте (номера, банкетный зал, пляж, события).
+// Одно фото может входить в несколько альбомов.
+type Albums struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug" gorm:"uniqueIndex"`
+	// CoverID — фото из галереи, которое показывается на обложке альбома.
+	CoverID    *int     `json:"cover_id"`
+	Cover      *Gallery `json:"cover,omitempty" gorm:"constraint:OnDelete:SET NULL"`
+	Visibility string   `json:"visibility"`
+	Position   int      `json:"position" gorm:"index"`
+}
+
+// AlbumPhotos — связь многие-ко-многим между альбомами и фото галереи.
+// Строки удаляются каскадно вместе с альбомом или фото.
+type AlbumPhotos struct {
+	AlbumID   int     `gorm:"primaryKey"`
+	GalleryID int     `gorm:"primaryKey;index"`
+	Album     Albums  `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Gallery   Gallery `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (AlbumPhotos) TableName() string {
+	return "album_photos"
+}
@@ -1,12 +1,22 @@
 package models
 
+// ContactsID — ключ единственной записи с контактами. Вторую запись не
+// пропустит ограничение contacts_singleton.
+const ContactsID = 1
+
+// Contacts — формат ответа GET /contacts, который ждёт старый фронтенд.
+// Сами данные теперь лежат в основной локации (Locations.Contacts), а прежняя
+// таблица contacts читается только при миграции.
 type Contacts struct {
-	Address           string `json:"address"`
-	Phone             string `json:"phone"`
-	Email             string `json:"email"`
-	Website           string `json:"website"`
-	WorkSchedule      string `json:"work_schedule"`
-	SocialMediaVK     string `json:"social_media_vk"`
-	SocialMediaYa     string `json:"social_media_ya"`
-	SocialMediaTwoGis string `json:"social_media_two_gis"`
+	ID           int    `json:"-" gorm:"primaryKey;autoIncrement:false;default:1;check:contacts_singleton,id = 1"`
+	Address      string `json:"address"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+	Website      string `json:"website"`
+	WorkSchedule string `json:"work_schedule"`
+	// OpeningHours есть только у локаций, в прежней таблице его не было.
+	OpeningHours      *OpeningHours `json:"opening_hours" gorm:"-"`
+	SocialMediaVK     string        `json:"social_media_vk"`
+	SocialMediaYa     string        `json:"social_media_ya"`
+	SocialMediaTwoGis string        `json:"social_media_two_gis"`
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date — календарная дата без времени. В JSON пишется как "2006-01-02",
+// в БД хранится в колонке типа date.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf отбрасывает время у t, сохраняя день по его часовому поясу.
+func DateOf(t time.Time) Date {
+	return NewDate(t.Year(), t.Month(), t.Day())
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("дата должна быть в формате ГГГГ-ММ-ДД: %q", s)
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseDate(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case string:
+		parsed, err := ParseDate(v)
+		*d = parsed
+		return err
+	case []byte:
+		parsed, err := ParseDate(string(v))
+		*d = parsed
+		return err
+	}
+	return fmt.Errorf("models: cannot scan %T into Date", value)
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (Date) GormDataType() string {
+	return "date"
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Files — загруженный файл, адресованный по содержимому. Одинаковые файлы
+// хранятся один раз, RefCount считает записи галереи, документов и
+// пользователей, которые на него ссылаются.
+type Files struct {
+	ID        int       `json:"id"`
+	Hash      string    `json:"hash" gorm:"uniqueIndex"`
+	Size      int64     `json:"size"`
+	MimeType  string    `json:"mime_type"`
+	Key       string    `json:"key" gorm:"uniqueIndex"`
+	RefCount  int       `json:"ref_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,7 +1,63 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Gallery struct {
 	ID       int    `json:"id"`
 	Filename string `json:"filename"`
 	Hidden   bool   `json:"hidden"`
+	// Position задаёт порядок фото на сайте, меняется через PATCH /gallery/order.
+	Position int    `json:"position" gorm:"index"`
+	Caption  string `json:"caption"`
+	// Alt — альтернативный текст для <img alt>, нужен для доступности и SEO.
+	Alt string `json:"alt"`
+	// TakenAt, Width, Height и Orientation берутся из EXIF при загрузке.
+	// Размеры указаны после поворота, Orientation — исходное значение из EXIF.
+	TakenAt     *time.Time       `json:"taken_at" gorm:"index"`
+	Width       int              `json:"width"`
+	Height      int              `json:"height"`
+	Orientation int              `json:"orientation"`
+	Variants    []GalleryVariant `json:"variants" gorm:"constraint:OnDelete:CASCADE"`
+	// Srcset готов для атрибута <img srcset>, собирается из JPEG-копий в
+	// Variants. SrcsetWebP — то же для WebP, для <source type="image/webp">
+	// внутри <picture>; пустой, если WebP-копий нет.
+	Srcset     string `json:"srcset" gorm:"-"`
+	SrcsetWebP string `json:"srcset_webp,omitempty" gorm:"-"`
+}
+
+// GalleryVariant — уменьшенная копия фото для адаптивной вёрстки.
+type GalleryVariant struct {
+	ID        int    `json:"-"`
+	GalleryID int    `json:"-" gorm:"index"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Format    string `json:"format"`
+	Filename  string `json:"filename"`
+}
+
+func (g *Gallery) AfterFind(tx *gorm.DB) error {
+	g.BuildSrcset()
+	return nil
+}
+
+// BuildSrcset заполняет Srcset и SrcsetWebP по загруженным Variants.
+func (g *Gallery) BuildSrcset() {
+	g.Srcset = srcset(g.Variants, "jpeg")
+	g.SrcsetWebP = srcset(g.Variants, "webp")
+}
+
+func srcset(variants []GalleryVariant, format string) string {
+	var parts []string
+	for _, v := range variants {
+		if v.Format == format {
+			parts = append(parts, fmt.Sprintf("%s %dw", v.Filename, v.Width))
+		}
+	}
+	return strings.Join(parts, ", ")
 }
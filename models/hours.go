@@ -0,0 +1,65 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Дни недели — ключи OpeningHours.Weekly.
+var WeekdayKeys = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// OpeningHours — график работы: интервалы по дням недели в часовом поясе
+// Timezone и исключения на конкретные даты (праздники, сокращённые дни).
+type OpeningHours struct {
+	// Timezone — часовой пояс IANA, например Europe/Moscow.
+	Timezone string `json:"timezone"`
+	// Weekly — интервалы по дням: ключи mon, tue, ..., sun. Нет ключа или
+	// пустой список — выходной.
+	Weekly     map[string][]Interval `json:"weekly"`
+	Exceptions []HoursException      `json:"exceptions"`
+}
+
+// Interval — время работы внутри дня в формате ЧЧ:ММ. Close может быть
+// "24:00", а если Close раньше Open, интервал заканчивается на следующий день.
+type Interval struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// HoursException заменяет обычный график в дату Date: Closed — выходной,
+// иначе действуют Intervals.
+type HoursException struct {
+	Date      Date       `json:"date"`
+	Closed    bool       `json:"closed"`
+	Intervals []Interval `json:"intervals"`
+	Note      string     `json:"note"`
+}
+
+// IsZero сообщает, что график пустой. В запросах пустой объект {} убирает
+// график, а null или отсутствие поля оставляют прежний.
+func (h *OpeningHours) IsZero() bool {
+	return h.Timezone == "" && len(h.Weekly) == 0 && len(h.Exceptions) == 0
+}
+
+func (h *OpeningHours) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into OpeningHours", value)
+	}
+	return json.Unmarshal(data, h)
+}
+
+func (h OpeningHours) Value() (driver.Value, error) {
+	data, err := json.Marshal(h)
+	return string(data), err
+}
+
+func (OpeningHours) GormDataType() string {
+	return "jsonb"
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultLocationName — название основной локации, созданной из прежних контактов.
+const DefaultLocationName = "Основная площадка"
+
+// Locations — площадка базы отдыха со своим адресом, телефонами и графиком.
+// Одна из них основная: её данные отдаёт GET /contacts.
+type Locations struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// Latitude и Longitude — координаты для карты, задаются вместе.
+	Latitude     *float64   `json:"latitude"`
+	Longitude    *float64   `json:"longitude"`
+	Phones       StringList `json:"phones"`
+	Emails       StringList `json:"emails"`
+	Website      string     `json:"website"`
+	WorkSchedule string     `json:"work_schedule"`
+	// OpeningHours — структурированный график; WorkSchedule остаётся
+	// текстом для показа на сайте.
+	OpeningHours      *OpeningHours `json:"opening_hours"`
+	SocialMediaVK     string        `json:"social_media_vk"`
+	SocialMediaYa     string        `json:"social_media_ya"`
+	SocialMediaTwoGis string        `json:"social_media_two_gis"`
+	// Primary отмечает основную площадку; частичный уникальный индекс не даёт
+	// отметить вторую.
+	Primary  bool `json:"primary" gorm:"column:is_primary;uniqueIndex:idx_locations_primary,where:is_primary"`
+	Position int  `json:"position" gorm:"index"`
+}
+
+// Contacts собирает из локации прежнюю запись контактов: первый телефон и
+// первый email становятся основными.
+func (l Locations) Contacts() Contacts {
+	return Contacts{
+		ID:                ContactsID,
+		Address:           l.Address,
+		Phone:             first(l.Phones),
+		Email:             first(l.Emails),
+		Website:           l.Website,
+		WorkSchedule:      l.WorkSchedule,
+		OpeningHours:      l.OpeningHours,
+		SocialMediaVK:     l.SocialMediaVK,
+		SocialMediaYa:     l.SocialMediaYa,
+		SocialMediaTwoGis: l.SocialMediaTwoGis,
+	}
+}
+
+// SetContacts записывает в локацию поля контактов. Телефон и email заменяют
+// первые элементы списков, остальные номера и адреса сохраняются. График
+// OpeningHours меняется, только если передан: старый фронтенд о нём не знает.
+// Пустой график убирает его.
+func (l *Locations) SetContacts(c Contacts) {
+	l.Address = c.Address
+	l.Phones = setFirst(l.Phones, c.Phone)
+	l.Emails = setFirst(l.Emails, c.Email)
+	l.Website = c.Website
+	l.WorkSchedule = c.WorkSchedule
+	switch {
+	case c.OpeningHours == nil:
+	case c.OpeningHours.IsZero():
+		l.OpeningHours = nil
+	default:
+		l.OpeningHours = c.OpeningHours
+	}
+	l.SocialMediaVK = c.SocialMediaVK
+	l.SocialMediaYa = c.SocialMediaYa
+	l.SocialMediaTwoGis = c.SocialMediaTwoGis
+}
+
+func first(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+	return list[0]
+}
+
+func setFirst(list StringList, value string) StringList {
+	switch {
+	case len(list) == 0 && value == "":
+		return list
+	case len(list) == 0:
+		return StringList{value}
+	case value == "":
+		return list[1:]
+	}
+	return append(StringList{value}, list[1:]...)
+}
+
+// StringList — список строк, в БД хранится как JSON-массив.
+type StringList []string
+
+func (s *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into StringList", value)
+	}
+	return json.Unmarshal(data, s)
+}
+
+func (s StringList) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+func (StringList) GormDataType() string {
+	return "jsonb"
+}
+
+// MarshalJSON отдаёт пустой список как [], а не null.
+func (s StringList) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(s))
+}
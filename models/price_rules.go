@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PriceRules — правило календаря цен: в указанные даты и дни недели услуга
+// стоит Amount вместо цены из прайса. Правило с датой начала в будущем —
+// это заранее запланированное изменение цены.
+type PriceRules struct {
+	ID        int       `json:"id"`
+	ServiceID int       `json:"service_id" gorm:"index"`
+	Service   *Services `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Label     string    `json:"label"`
+	// DateFrom и DateTo — период действия правила, включительно.
+	// Пустые значения — без ограничения с этой стороны.
+	DateFrom *Date `json:"date_from"`
+	DateTo   *Date `json:"date_to"`
+	// Weekdays — дни недели, в которые действует правило; пусто — все дни.
+	Weekdays Weekdays `json:"weekdays"`
+	// Priority решает, какое из подходящих правил сработает: больше — важнее.
+	Priority int    `json:"priority"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Unit     string `json:"unit"`
+}
+
+// Matches сообщает, действует ли правило в день day.
+func (r PriceRules) Matches(day Date) bool {
+	if r.DateFrom != nil && day.Before(r.DateFrom.Time) {
+		return false
+	}
+	if r.DateTo != nil && day.After(r.DateTo.Time) {
+		return false
+	}
+	return r.Weekdays.Has(day.Weekday())
+}
+
+// Weekdays — набор дней недели. В JSON это массив номеров по ISO 8601
+// (1 — понедельник, 7 — воскресенье), в БД — битовая маска.
+type Weekdays uint8
+
+// isoDay переводит time.Weekday в номер по ISO 8601.
+func isoDay(d time.Weekday) int {
+	if d == time.Sunday {
+		return 7
+	}
+	return int(d)
+}
+
+// Has сообщает, входит ли день в набор. Пустой набор означает «все дни».
+func (w Weekdays) Has(d time.Weekday) bool {
+	return w == 0 || w&(1<<(isoDay(d)-1)) != 0
+}
+
+func (w Weekdays) MarshalJSON() ([]byte, error) {
+	days := []int{}
+	for day := 1; day <= 7; day++ {
+		if w&(1<<(day-1)) != 0 {
+			days = append(days, day)
+		}
+	}
+	return json.Marshal(days)
+}
+
+func (w *Weekdays) UnmarshalJSON(data []byte) error {
+	var days []int
+	if err := json.Unmarshal(data, &days); err != nil {
+		return err
+	}
+	var mask Weekdays
+	for _, day := range days {
+		if day < 1 || day > 7 {
+			return fmt.Errorf("день недели должен быть от 1 (понедельник) до 7 (воскресенье): %d", day)
+		}
+		mask |= 1 << (day - 1)
+	}
+	*w = mask
+	return nil
+}
+
+func (w *Weekdays) Scan(value interface{}) error {
+	n, ok := value.(int64)
+	if !ok {
+		return fmt.Errorf("models: cannot scan %T into Weekdays", value)
+	}
+	*w = Weekdays(n)
+	return nil
+}
+
+func (w Weekdays) Value() (driver.Value, error) {
+	return int64(w), nil
+}
+
+func (Weekdays) GormDataType() string {
+	return "smallint"
+}
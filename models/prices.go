@@ -0,0 +1,67 @@
+package models
+
+// Единицы, за которые берётся цена.
+const (
+	UnitFixed  = "fixed"
+	UnitNight  = "night"
+	UnitDay    = "day"
+	UnitHour   = "hour"
+	UnitPerson = "person"
+)
+
+const CurrencyRUB = "RUB"
+
+// ServicePrices — одна строка прайса услуги, например «Будни, 3000 ₽ в час».
+type ServicePrices struct {
+	ID        int    `json:"id"`
+	ServiceID int    `json:"service_id" gorm:"index"`
+	Label     string `json:"label"`
+	// Amount хранится в копейках (минимальных единицах валюты), чтобы не
+	// связываться с дробными числами.
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Unit     string `json:"unit"`
+	// SeasonFrom и SeasonTo ограничивают период действия цены, включительно.
+	// Пустые значения — без ограничения с этой стороны.
+	SeasonFrom *Date `json:"season_from"`
+	SeasonTo   *Date `json:"season_to"`
+}
+
+// ValidUnit сообщает, известна ли единица цены.
+func ValidUnit(unit string) bool {
+	switch unit {
+	case UnitFixed, UnitNight, UnitDay, UnitHour, UnitPerson:
+		return true
+	}
+	return false
+}
+
+// InSeason сообщает, действует ли цена в день day.
+func (p ServicePrices) InSeason(day Date) bool {
+	if p.SeasonFrom != nil && day.Before(p.SeasonFrom.Time) {
+		return false
+	}
+	if p.SeasonTo != nil && day.After(p.SeasonTo.Time) {
+		return false
+	}
+	return true
+}
+
+// minPrice выбирает самую низкую цену из действующих сегодня, а если
+// сегодня не действует ни одна — из всех.
+func minPrice(prices []ServicePrices, today Date) *ServicePrices {
+	var min, minAny *ServicePrices
+	for i := range prices {
+		p := &prices[i]
+		if minAny == nil || p.Amount < minAny.Amount {
+			minAny = p
+		}
+		if p.InSeason(today) && (min == nil || p.Amount < min.Amount) {
+			min = p
+		}
+	}
+	if min == nil {
+		return minAny
+	}
+	return min
+}
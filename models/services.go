@@ -1,10 +1,65 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Services struct {
-	ID     int    `json:"id"`
-	Eng    string `json:"eng"`
-	Title  string `json:"title"`
-	Prices string `json:"prices"`
-	Src    string `json:"src"`
-	Text   string `json:"text"`
+	ID    int    `json:"id"`
+	Eng   string `json:"eng" gorm:"uniqueIndex"`
+	Title string `json:"title"`
+	// Prices — прежнее текстовое описание цены. Структурированный прайс
+	// лежит в PriceList, перенести старые строки помогает команда migrate-prices.
+	Prices    string          `json:"prices"`
+	PriceList []ServicePrices `json:"price_list" gorm:"foreignKey:ServiceID;constraint:OnDelete:CASCADE"`
+	// MinPrice — самая низкая действующая цена для подписи «от N ₽»,
+	// вычисляется по PriceList.
+	MinPrice *ServicePrices `json:"min_price" gorm:"-"`
+	// Src — адрес картинки, введённый вручную; отдаётся как сохранён.
+	Src string `json:"src"`
+	// CoverID и Images ссылаются на фото галереи; пока фото используется
+	// услугой, удалить его из галереи нельзя.
+	CoverID *int     `json:"cover_id"`
+	Cover   *Gallery `json:"cover,omitempty" gorm:"constraint:OnDelete:RESTRICT"`
+	// CoverURL — адрес обложки, если она задана.
+	CoverURL string          `json:"cover_url,omitempty" gorm:"-"`
+	Images   []ServiceImages `json:"images" gorm:"foreignKey:ServiceID;constraint:OnDelete:CASCADE"`
+	// Text — описание в Markdown; HTML внутри допускается только из
+	// безопасного набора тегов и очищается при сохранении.
+	Text string `json:"text"`
+	// TextHTML — Text, отрендеренный в безопасный HTML. Заполняется только
+	// по запросу ?render=html.
+	TextHTML string `json:"text_html,omitempty" gorm:"-"`
+}
+
+// ServiceImages — фото галереи в карточке услуги, в порядке Position.
+type ServiceImages struct {
+	ServiceID int      `json:"-" gorm:"primaryKey"`
+	GalleryID int      `json:"gallery_id" gorm:"primaryKey;index"`
+	Position  int      `json:"position"`
+	Gallery   *Gallery `json:"gallery,omitempty" gorm:"constraint:OnDelete:RESTRICT"`
+}
+
+func (ServiceImages) TableName() string {
+	return "service_images"
+}
+
+// TodaySetting — ключ gorm-настройки (db.Set) с сегодняшней датой базы отдыха,
+// по ней считается MinPrice. Без неё берётся дата по часам сервера.
+const TodaySetting = "models:today"
+
+func (s *Services) AfterFind(tx *gorm.DB) error {
+	today := DateOf(time.Now())
+	if v, ok := tx.Get(TodaySetting); ok {
+		if d, ok := v.(Date); ok {
+			today = d
+		}
+	}
+	s.MinPrice = minPrice(s.PriceList, today)
+	if s.Cover != nil {
+		s.CoverURL = s.Cover.Filename
+	}
+	return nil
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Sessions хранит выданные токены входа. В БД лежит только SHA-256 от токена,
+// сам токен знает лишь клиент.
+type Sessions struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id" gorm:"index"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,10 +1,20 @@
 package models
 
+const (
+	RoleAdmin  = "Admin"
+	RoleEditor = "Editor"
+	RoleUser   = "User"
+
+	StatusActive  = "Active"
+	StatusBlocked = "Blocked"
+)
+
 type Users struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	Status string `json:"status"`
-	Avatar string `json:"avatar"`
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email" gorm:"uniqueIndex"`
+	Role         string `json:"role"`
+	Status       string `json:"status"`
+	Avatar       string `json:"avatar"`
+	PasswordHash string `json:"-"`
 }